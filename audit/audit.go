@@ -0,0 +1,90 @@
+// Package audit checks the records of a zone for weak spots, and reports them
+// as findings with a severity.
+//
+// The checks only look at the records they are given, as returned by
+// porkbun.Client.ListDnsRecords for a whole domain. Names outside the zone,
+// such as a mail server at another provider, are not resolved.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity says how urgently a finding needs fixing.
+type Severity int
+
+const (
+	Info Severity = iota
+	Low
+	Medium
+	High
+	Critical
+)
+
+var severityNames = []string{"info", "low", "medium", "high", "critical"}
+
+func (s Severity) String() string {
+	if s < Info || s > Critical {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+
+	return severityNames[s]
+}
+
+// ParseSeverity reads a severity by name, such as "high".
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(n, name) {
+			return Severity(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown severity %q, want one of %s", name, strings.Join(severityNames, ", "))
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	parsed, err := ParseSeverity(name)
+	if err != nil {
+		return err
+	}
+
+	*s = parsed
+	return nil
+}
+
+// Finding is a problem found by a check.
+type Finding struct {
+	Domain string `json:"domain"`
+
+	// The record, or the name a missing record should have.
+	Name string `json:"name"`
+
+	// A short id of the check, such as "dmarc-missing", stable enough to
+	// filter on.
+	Check string `json:"check"`
+
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// sortFindings orders findings from most to least severe, then by name.
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity > findings[j].Severity
+		}
+		return findings[i].Name < findings[j].Name
+	})
+}
@@ -0,0 +1,120 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/audit"
+)
+
+// A 1024 bit key, as still published by many providers.
+const shortKey = "MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDDsZ8yBPhcTY4bkoJpRv0vqySOESjfSexVAXAzrBqPUxSrjQMagCAlU2EvlWpc6TlN8tKVAE4g8HCuU2Qa3WV/PMZcbFBN4g3dpM0H+DUt0l0vvDyh1IeJ7/vDRgcnOcvfYGu9EQmMUJ+SCeMRwTeO+Wtre/iMm0JZkNZqnFvjOQIDAQAB"
+
+func TestEmail(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		records []porkbun.Record
+		want    []string
+	}{
+		{
+			msg: "protected",
+			records: []porkbun.Record{
+				{Name: "example.com", Type: "MX", Content: "mx.example.net"},
+				{Name: "example.com", Type: "TXT", Content: "v=spf1 include:_spf.example.net -all"},
+				{Name: "_dmarc.example.com", Type: "TXT", Content: "v=DMARC1; p=reject"},
+				{Name: "_mta-sts.example.com", Type: "TXT", Content: "v=STSv1; id=1"},
+			},
+		},
+		{
+			msg: "parked and protected",
+			records: []porkbun.Record{
+				{Name: "example.com", Type: "MX", Content: "."},
+				{Name: "example.com", Type: "TXT", Content: "v=spf1 -all"},
+				{Name: "_dmarc.example.com", Type: "TXT", Content: "v=DMARC1; p=reject"},
+			},
+		},
+		{
+			msg:     "parked and open",
+			records: []porkbun.Record{{Name: "example.com", Type: "A", Content: "192.0.2.1"}},
+			want:    []string{"dmarc-missing", "spf-missing"},
+		},
+		{
+			msg: "parked and soft",
+			records: []porkbun.Record{
+				{Name: "example.com", Type: "TXT", Content: "v=spf1 ~all"},
+				{Name: "_dmarc.example.com", Type: "TXT", Content: "v=DMARC1; p=quarantine"},
+			},
+			want: []string{"dmarc-parked", "spf-parked"},
+		},
+		{
+			msg: "weak",
+			records: []porkbun.Record{
+				{Name: "example.com", Type: "MX", Content: "mail.example.com"},
+				{Name: "mail.example.com", Type: "CNAME", Content: "mx.example.net"},
+				{Name: "example.com", Type: "TXT", Content: "v=spf1 mx +all"},
+				{Name: "_dmarc.example.com", Type: "TXT", Content: "v=DMARC1; p=none"},
+				{Name: "s1._domainkey.example.com", Type: "TXT", Content: "v=DKIM1; k=rsa; p=" + shortKey},
+				{Name: "old._domainkey.example.com", Type: "TXT", Content: "v=DKIM1; p="},
+			},
+			want: []string{"spf-pass-all", "mx-cname", "dmarc-none", "dkim-short-key", "mta-sts-missing"},
+		},
+		{
+			msg: "neutral and duplicated",
+			records: []porkbun.Record{
+				{Name: "example.com", Type: "MX", Content: "mx.example.net"},
+				{Name: "example.com", Type: "TXT", Content: "v=spf1 ?all"},
+				{Name: "_dmarc.example.com", Type: "TXT", Content: "v=DMARC1; p=reject"},
+				{Name: "_dmarc.example.com", Type: "TXT", Content: "v=DMARC1; p=none"},
+				{Name: "_mta-sts.example.com", Type: "TXT", Content: "v=STSv1; id=1"},
+			},
+			want: []string{"dmarc-invalid", "spf-neutral-all"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			var got []string
+			for _, finding := range audit.Email("example.com", tc.records) {
+				if finding.Domain != "example.com" {
+					t.Errorf("got domain %q, want %q", finding.Domain, "example.com")
+				}
+				got = append(got, finding.Check)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	finding := audit.Finding{Check: "spf-pass-all", Severity: audit.Critical}
+
+	data, err := json.Marshal(finding)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["severity"] != "critical" {
+		t.Errorf("got %v, want %q", got["severity"], "critical")
+	}
+
+	var again audit.Finding
+	if err := json.Unmarshal(data, &again); err != nil || again != finding {
+		t.Errorf("got %+v %v, want %+v", again, err, finding)
+	}
+
+	if s, err := audit.ParseSeverity("HIGH"); err != nil || s != audit.High {
+		t.Errorf("got %s %v, want high nil", s, err)
+	}
+	if _, err := audit.ParseSeverity("urgent"); err == nil {
+		t.Error("got nil, want an error")
+	}
+}
@@ -0,0 +1,202 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/dkim"
+	"github.com/andrew-womeldorf/porkbun-go/mailpolicy"
+	"github.com/andrew-womeldorf/porkbun-go/spf"
+)
+
+// RSA DKIM keys shorter than this are weak, and shorter than minDKIMBits are
+// broken.
+const (
+	goodDKIMBits = 2048
+	minDKIMBits  = 1024
+)
+
+// Email checks how well a domain is protected against spoofed mail: its SPF,
+// DMARC, DKIM, MX and MTA-STS records.
+//
+// A domain without MX records, or with only a null MX, is taken to be parked.
+// Nobody sends mail from a parked domain, so it should say so, with an SPF
+// record ending in -all and a DMARC policy of reject.
+func Email(domain string, records []porkbun.Record) []Finding {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	z := zone{domain: domain, records: records}
+
+	var findings []Finding
+	add := func(name, check string, severity Severity, format string, args ...any) {
+		findings = append(findings, Finding{
+			Domain:   domain,
+			Name:     name,
+			Check:    check,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	mx := z.find(domain, "MX")
+	parked := true
+	for _, record := range mx {
+		if host := trimDot(record.Content); host != "" {
+			parked = false
+		}
+	}
+
+	// SPF
+	current, err := spf.Find(z.find(domain, "TXT"))
+	switch {
+	case errors.Is(err, spf.ErrNoRecord):
+		add(domain, "spf-missing", High, "no SPF record, so anyone may send mail as %s", domain)
+	case err != nil:
+		add(domain, "spf-invalid", High, "%s", err)
+	default:
+		checkSPF(current.Content, parked, func(check string, severity Severity, format string, args ...any) {
+			add(domain, check, severity, format, args...)
+		})
+	}
+
+	// DMARC
+	dmarcName := mailpolicy.DMARCName(domain)
+	var dmarcs []porkbun.Record
+	for _, record := range z.find(dmarcName, "TXT") {
+		if strings.HasPrefix(record.TextValue(), "v=DMARC1") {
+			dmarcs = append(dmarcs, record)
+		}
+	}
+	switch len(dmarcs) {
+	case 0:
+		add(dmarcName, "dmarc-missing", High, "no DMARC record, so receivers don't act on failed SPF or DKIM")
+	case 1:
+		policy, err := mailpolicy.ParseDMARC(dmarcs[0].Content)
+		switch {
+		case err != nil:
+			add(dmarcName, "dmarc-invalid", High, "DMARC record is invalid, %s", err)
+		case policy.Policy == mailpolicy.None:
+			add(dmarcName, "dmarc-none", Medium, "DMARC policy is p=none, which only monitors")
+		case parked && policy.Policy != mailpolicy.Reject:
+			add(dmarcName, "dmarc-parked", Medium, "DMARC policy is p=%s; a domain that sends no mail should reject", policy.Policy)
+		}
+	default:
+		add(dmarcName, "dmarc-invalid", High, "%d DMARC records; receivers ignore them all", len(dmarcs))
+	}
+
+	// DKIM
+	for _, record := range records {
+		if !strings.EqualFold(record.Type, "TXT") {
+			continue
+		}
+		selector, ok := dkim.Selector(record.Name)
+		if !ok || !z.inZone(record.Name) {
+			continue
+		}
+
+		parsed, err := dkim.Parse(record.Content)
+		if err != nil {
+			add(record.Name, "dkim-invalid", Low, "DKIM selector %q is invalid, %s", selector, err)
+			continue
+		}
+		if parsed.KeyType() != dkim.RSA {
+			continue
+		}
+
+		bits, err := parsed.Bits()
+		switch {
+		case err != nil:
+			add(record.Name, "dkim-invalid", Low, "DKIM selector %q has an unreadable key, %s", selector, err)
+		case bits == 0:
+			// A revoked key.
+		case bits < minDKIMBits:
+			add(record.Name, "dkim-short-key", High, "DKIM selector %q has a %d bit key, which can be broken", selector, bits)
+		case bits < goodDKIMBits:
+			add(record.Name, "dkim-short-key", Medium, "DKIM selector %q has a %d bit key; use %d bits", selector, bits, goodDKIMBits)
+		}
+	}
+
+	// MX
+	for _, record := range records {
+		if !strings.EqualFold(record.Type, "MX") || !z.inZone(record.Name) {
+			continue
+		}
+
+		host := trimDot(record.Content)
+		if host != "" && len(z.find(host, "CNAME")) > 0 {
+			add(record.Name, "mx-cname", High, "MX points at %s, which is a CNAME; mail servers may refuse it", host)
+		}
+	}
+
+	// MTA-STS
+	if !parked {
+		name := mailpolicy.MTASTSName(domain)
+		found := false
+		for _, record := range z.find(name, "TXT") {
+			if _, err := mailpolicy.ParseMTASTS(record.Content); err == nil {
+				found = true
+			}
+		}
+		if !found {
+			add(name, "mta-sts-missing", Low, "no MTA-STS record, so mail to %s may be sent without TLS", domain)
+		}
+	}
+
+	sortFindings(findings)
+	return findings
+}
+
+// checkSPF checks the all mechanism of an SPF record.
+func checkSPF(content string, parked bool, add func(check string, severity Severity, format string, args ...any)) {
+	record, err := spf.Parse(content)
+	if err != nil {
+		add("spf-invalid", High, "SPF record is invalid, %s", err)
+		return
+	}
+
+	all, ok := record.All()
+	if !ok {
+		if _, ok := record.Modifier("redirect"); !ok {
+			add("spf-no-all", Medium, "SPF record has no all mechanism, so it passes no judgement on other senders")
+		}
+		return
+	}
+
+	switch all.Qualifier {
+	case spf.Pass:
+		add("spf-pass-all", Critical, "SPF record ends in +all, allowing anyone to send mail")
+	case spf.Neutral:
+		add("spf-neutral-all", High, "SPF record ends in ?all, which says nothing about other senders")
+	case spf.SoftFail:
+		if parked {
+			add("spf-parked", Medium, "SPF record ends in ~all; a domain that sends no mail should use -all")
+		}
+	}
+}
+
+// zone looks up records by name and type. Porkbun returns full names.
+type zone struct {
+	domain  string
+	records []porkbun.Record
+}
+
+func (z zone) find(name, recordType string) []porkbun.Record {
+	var found []porkbun.Record
+	for _, record := range z.records {
+		if strings.EqualFold(trimDot(record.Name), name) && strings.EqualFold(record.Type, recordType) {
+			found = append(found, record)
+		}
+	}
+
+	return found
+}
+
+func (z zone) inZone(name string) bool {
+	name = strings.ToLower(trimDot(name))
+	return name == z.domain || strings.HasSuffix(name, "."+z.domain)
+}
+
+func trimDot(name string) string {
+	return strings.TrimSuffix(strings.TrimSpace(name), ".")
+}
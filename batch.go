@@ -0,0 +1,125 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// The number of operations Batch runs at once, unless told otherwise.
+const defaultConcurrency = 4
+
+type OperationKind string
+
+const (
+	OperationCreate OperationKind = "create"
+	OperationModify OperationKind = "modify"
+	OperationDelete OperationKind = "delete"
+)
+
+// Operation is a single change to a DNS record.
+type Operation struct {
+	Kind   OperationKind `json:"kind"`
+	Domain string        `json:"domain"`
+
+	// The record to create or modify, as passed to CreateDnsRecord or
+	// ModifyDnsRecord. To delete, set the Id, or the Name and Type to delete
+	// every record that matches them.
+	Record Record `json:"record"`
+}
+
+func (o Operation) String() string {
+	return fmt.Sprintf("%s %q %q on %q", o.Kind, o.Record.Name, o.Record.Type, o.Domain)
+}
+
+type OperationResult struct {
+	Operation Operation `json:"operation"`
+
+	// The Id of the record that was created.
+	Id RecordID `json:"id,omitempty"`
+
+	// Why the operation failed, or nil if it succeeded. Operations that never
+	// ran because the context was done hold the context's error.
+	Err error `json:"-"`
+}
+
+// apply runs a single operation against the api.
+func (c *Client) apply(ctx context.Context, op Operation) (RecordID, error) {
+	switch op.Kind {
+	case OperationCreate:
+		res, err := c.CreateDnsRecord(ctx, op.Domain, &op.Record)
+		if err != nil {
+			return "", err
+		}
+		return res.Id, nil
+	case OperationModify:
+		_, err := c.ModifyDnsRecord(ctx, op.Domain, &op.Record)
+		return op.Record.Id, err
+	case OperationDelete:
+		var err error
+		if op.Record.Id != "" {
+			_, err = c.DeleteDnsRecordById(ctx, op.Domain, op.Record.Id)
+		} else {
+			_, err = c.DeleteDnsRecordByLookup(ctx, op.Domain, op.Record.Name, op.Record.Type)
+		}
+		return op.Record.Id, err
+	default:
+		return "", fmt.Errorf("unknown operation kind %q", op.Kind)
+	}
+}
+
+// Batch runs many operations, at most concurrency at a time, in no particular
+// order. Zero or less uses a default. A failed operation does not stop the
+// others; once ctx is done, no more operations are started.
+//
+// There is one result per operation, in the same order as ops. The error joins
+// the errors of every operation that failed, and is nil if they all
+// succeeded.
+func (c *Client) Batch(ctx context.Context, ops []Operation, concurrency int) ([]OperationResult, error) {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	results := make([]OperationResult, len(ops))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, op := range ops {
+		results[i].Operation = op
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		// The context may be done while waiting for a slot, and select picks
+		// at random when both are ready.
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			<-sem
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, op Operation) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i].Id, results[i].Err = c.apply(ctx, op)
+		}(i, op)
+	}
+
+	wg.Wait()
+
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("operation %d, %s, %w", i, result.Operation, result.Err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
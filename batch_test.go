@@ -0,0 +1,137 @@
+package porkbun_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestBatch(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("results", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+		defer server.Close()
+		id, _ := server.Backend.AddRecord("example.com", porkbun.Record{Name: "old", Type: "A", Content: "10.0.0.1"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "api", Type: "A", Content: "10.0.0.2"})
+
+		ops := []porkbun.Operation{
+			{Kind: porkbun.OperationCreate, Domain: "example.com", Record: porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}},
+			{Kind: porkbun.OperationCreate, Domain: "example.org", Record: porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}},
+			{Kind: porkbun.OperationModify, Domain: "example.com", Record: porkbun.Record{Name: "api", Type: "A", Content: "10.0.0.4"}},
+			{Kind: porkbun.OperationDelete, Domain: "example.com", Record: porkbun.Record{Id: id}},
+		}
+
+		results, err := newTestClient(t, server).Batch(ctx, ops, 2)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		if len(results) != len(ops) {
+			t.Fatalf("got %d results, want %d", len(results), len(ops))
+		}
+
+		for i, result := range results {
+			if result.Operation != ops[i] {
+				t.Errorf("got %v, want results in order", result.Operation)
+			}
+
+			if failed := result.Err != nil; failed != (i == 1) {
+				t.Errorf("operation %d: got %v", i, result.Err)
+			}
+		}
+
+		if results[0].Id == "" {
+			t.Errorf("got empty id for created record")
+		}
+
+		var apiErr *porkbun.ApiError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("got %v, want joined ApiError", err)
+		}
+
+		records := server.Backend.Records("example.com")
+		if len(records) != 2 || records[0].Content != "10.0.0.4" || records[1].Content != "10.0.0.3" {
+			t.Errorf("got %+v, want api modified, old deleted and www created", records)
+		}
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		backend := porkbuntest.NewBackend(porkbuntest.WithDomains("example.com"))
+
+		var inFlight, peak int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			backend.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		client, _ := porkbun.NewClient(
+			porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+			porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+			porkbun.WithBaseUrl(server.URL),
+		)
+
+		var ops []porkbun.Operation
+		for i := 0; i < 20; i++ {
+			ops = append(ops, porkbun.Operation{
+				Kind:   porkbun.OperationCreate,
+				Domain: "example.com",
+				Record: porkbun.Record{Name: fmt.Sprintf("host%d", i), Type: "A", Content: "10.0.0.1"},
+			})
+		}
+
+		if _, err := client.Batch(ctx, ops, 3); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if peak > 3 {
+			t.Errorf("got %d requests at once, want at most 3", peak)
+		}
+
+		if records := backend.Records("example.com"); len(records) != 20 {
+			t.Errorf("got %d records, want 20", len(records))
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		results, err := newTestClient(t, server).Batch(ctx, []porkbun.Operation{
+			{Kind: porkbun.OperationCreate, Domain: "example.com", Record: porkbun.Record{Type: "A", Content: "10.0.0.1"}},
+		}, 1)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+
+		if !errors.Is(results[0].Err, context.Canceled) {
+			t.Errorf("got %v, want %v", results[0].Err, context.Canceled)
+		}
+
+		if calls := server.Backend.Calls(""); calls != 0 {
+			t.Errorf("got %d calls, want 0", calls)
+		}
+	})
+}
@@ -0,0 +1,398 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+// How long a rollback may take. It runs even if the context given to Apply
+// is done, as that may be why a change failed.
+const rollbackTimeout = 2 * time.Minute
+
+type ChangeState string
+
+const (
+	// The change was made and kept.
+	ChangeApplied ChangeState = "applied"
+
+	// The change was made, then undone after a later change failed.
+	ChangeRolledBack ChangeState = "rolled-back"
+
+	// The change was made, and undoing it failed. Its records are left as the
+	// change made them.
+	ChangeRollbackFailed ChangeState = "rollback-failed"
+
+	// The change failed, and the records show it was not made.
+	ChangeFailed ChangeState = "failed"
+
+	// The change was never attempted, because an earlier one failed.
+	ChangeSkipped ChangeState = "skipped"
+
+	// The change failed in a way that may have reached Porkbun anyway, and
+	// listing the records to find out failed too.
+	ChangeUnknown ChangeState = "unknown"
+)
+
+type ChangeResult struct {
+	Operation Operation   `json:"operation"`
+	State     ChangeState `json:"state"`
+
+	// The records the change affected, before it was applied. Empty for a
+	// create.
+	Before []Record `json:"before"`
+
+	// The records as they ended up. Records deleted and then recreated by a
+	// rollback have new ids.
+	After []Record `json:"after"`
+
+	Err error `json:"-"`
+}
+
+type ChangeSetReport struct {
+	Results []ChangeResult `json:"results"`
+}
+
+// ChangeSet applies a list of changes in order, all or nothing. If any change
+// fails, the changes already made are undone in reverse order: created
+// records are deleted, modified records are restored, and deleted records are
+// recreated.
+//
+// Porkbun has no transactions, so other writers can still observe the
+// changes before they are rolled back, and a rollback can itself fail. The
+// report says what happened to each change.
+type ChangeSet struct {
+	client     *Client
+	operations []Operation
+}
+
+func (c *Client) NewChangeSet(ops ...Operation) *ChangeSet {
+	return &ChangeSet{client: c, operations: ops}
+}
+
+// Add appends changes to the set.
+func (cs *ChangeSet) Add(ops ...Operation) *ChangeSet {
+	cs.operations = append(cs.operations, ops...)
+	return cs
+}
+
+// Create adds a record to create.
+func (cs *ChangeSet) Create(domain string, record Record) *ChangeSet {
+	return cs.Add(Operation{Kind: OperationCreate, Domain: domain, Record: record})
+}
+
+// Modify adds a change to a record, found by Id or by Name and Type.
+func (cs *ChangeSet) Modify(domain string, record Record) *ChangeSet {
+	return cs.Add(Operation{Kind: OperationModify, Domain: domain, Record: record})
+}
+
+// Delete adds a record to delete, found by Id or by Name and Type.
+func (cs *ChangeSet) Delete(domain string, record Record) *ChangeSet {
+	return cs.Add(Operation{Kind: OperationDelete, Domain: domain, Record: record})
+}
+
+// Apply snapshots the records of every domain in the set, then makes each
+// change in order. Nothing is changed if a record to modify or delete is not
+// there, in the snapshot or as the changes before it leave the records, so a
+// change may modify or delete a record an earlier one created.
+//
+// A change that fails may still have reached Porkbun, as when the connection
+// drops or ctx is done after the request was sent. The records are listed
+// again to find out, and a change that was made is rolled back with the rest.
+// The rollback runs even if ctx is done, under a timeout of its own.
+func (cs *ChangeSet) Apply(ctx context.Context) (*ChangeSetReport, error) {
+	report := &ChangeSetReport{Results: make([]ChangeResult, len(cs.operations))}
+	for i, op := range cs.operations {
+		report.Results[i] = ChangeResult{Operation: op, State: ChangeSkipped}
+	}
+
+	live := map[string][]Record{}
+	for _, op := range cs.operations {
+		if _, ok := live[op.Domain]; ok {
+			continue
+		}
+
+		res, err := cs.client.ListDnsRecords(ctx, op.Domain, "", "")
+		if err != nil {
+			return report, fmt.Errorf("err snapshotting records of %q, %w", op.Domain, err)
+		}
+		live[op.Domain] = res.Records
+	}
+
+	planned := maps.Clone(live)
+	for i, op := range cs.operations {
+		if op.Kind != OperationCreate && len(affected(planned[op.Domain], op)) == 0 {
+			return report, fmt.Errorf("operation %d, %s, no record found", i, op)
+		}
+		planned[op.Domain] = change(planned[op.Domain], op, "")
+	}
+
+	for i := range report.Results {
+		result := &report.Results[i]
+		op := result.Operation
+
+		result.Before = affected(live[op.Domain], op)
+		result.After = result.Before
+
+		id, err := cs.client.apply(ctx, op)
+		if err != nil {
+			result.State = ChangeFailed
+			result.Err = err
+
+			rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+			defer cancel()
+
+			return report, errors.Join(
+				fmt.Errorf("operation %d, %s, %w", i, op, err),
+				cs.landed(rollbackCtx, i, result, live[op.Domain]),
+				cs.rollback(rollbackCtx, report.Results[:i+1]),
+			)
+		}
+
+		result.State = ChangeApplied
+		result.After = applied(op, result.Before, id)
+		live[op.Domain] = change(live[op.Domain], op, id)
+	}
+
+	return report, nil
+}
+
+// landed lists the records of a failed change again, to find out whether it
+// was made after all, and marks it applied if it was. records are the records
+// of the domain from before the change.
+func (cs *ChangeSet) landed(ctx context.Context, i int, result *ChangeResult, records []Record) error {
+	op := result.Operation
+
+	res, err := cs.client.ListDnsRecords(ctx, op.Domain, "", "")
+	if err != nil {
+		result.State = ChangeUnknown
+		return fmt.Errorf("err checking whether operation %d, %s, was made, %w", i, op, err)
+	}
+
+	current := map[RecordID]Record{}
+	for _, record := range res.Records {
+		current[record.Id] = record
+	}
+
+	switch op.Kind {
+	case OperationCreate:
+		known := map[RecordID]bool{}
+		for _, record := range records {
+			known[record.Id] = true
+		}
+
+		for _, record := range res.Records {
+			if !known[record.Id] && record.Name == fqdn(op.Record.Name, op.Domain) &&
+				strings.EqualFold(record.Type, op.Record.Type) && sameContent(record.Type, record.Content, op.Record.Content) {
+				result.State = ChangeApplied
+				result.After = []Record{record}
+				return nil
+			}
+		}
+	case OperationModify, OperationDelete:
+		var after []Record
+		for _, before := range result.Before {
+			record, ok := current[before.Id]
+			if !ok || record != before {
+				result.State = ChangeApplied
+			}
+			if ok {
+				after = append(after, record)
+			}
+		}
+		if result.State == ChangeApplied {
+			result.After = after
+		}
+	}
+
+	return nil
+}
+
+// rollback undoes applied changes, newest first. Records recreated by undoing
+// a delete get new ids, which undoing the changes before it then uses.
+func (cs *ChangeSet) rollback(ctx context.Context, results []ChangeResult) error {
+	var errs []error
+	ids := map[RecordID]RecordID{}
+	id := func(id RecordID) RecordID {
+		if recreated, ok := ids[id]; ok {
+			return recreated
+		}
+		return id
+	}
+
+	for i := len(results) - 1; i >= 0; i-- {
+		result := &results[i]
+		if result.State != ChangeApplied {
+			continue
+		}
+		domain := result.Operation.Domain
+
+		var err error
+		switch result.Operation.Kind {
+		case OperationCreate:
+			var left []Record
+			for _, created := range result.After {
+				if err == nil {
+					_, err = cs.client.DeleteDnsRecordById(ctx, domain, id(created.Id))
+				}
+				if err != nil {
+					left = append(left, created)
+				}
+			}
+			result.After = left
+		case OperationModify:
+			var restored []Record
+			for _, before := range result.Before {
+				restore := before
+				restore.Id = id(before.Id)
+				restore.Name = relativeName(before.Name, domain)
+				if _, err = cs.client.ModifyDnsRecord(ctx, domain, &restore); err != nil {
+					break
+				}
+
+				restore.Name = before.Name
+				restored = append(restored, restore)
+			}
+			if err == nil {
+				result.After = restored
+			}
+		case OperationDelete:
+			left := map[RecordID]bool{}
+			for _, after := range result.After {
+				left[after.Id] = true
+			}
+
+			recreated := result.After
+			for _, before := range result.Before {
+				if left[before.Id] {
+					continue
+				}
+
+				restore := before
+				restore.Id = ""
+				restore.Name = relativeName(before.Name, domain)
+
+				var res *CreateDnsRecordResponse
+				if res, err = cs.client.CreateDnsRecord(ctx, domain, &restore); err != nil {
+					break
+				}
+
+				ids[before.Id] = res.Id
+				restore.Id = res.Id
+				restore.Name = before.Name
+				recreated = append(recreated, restore)
+			}
+			result.After = recreated
+		}
+
+		if err != nil {
+			result.State = ChangeRollbackFailed
+			result.Err = err
+			errs = append(errs, fmt.Errorf("err rolling back operation %d, %s, %w", i, result.Operation, err))
+			continue
+		}
+
+		result.State = ChangeRolledBack
+	}
+
+	return errors.Join(errs...)
+}
+
+// matches reports whether op modifies or deletes record.
+func matches(record Record, op Operation) bool {
+	if op.Kind == OperationCreate {
+		return false
+	}
+
+	if op.Record.Id != "" {
+		return record.Id == op.Record.Id
+	}
+
+	return record.Name == fqdn(op.Record.Name, op.Domain) && strings.EqualFold(record.Type, op.Record.Type)
+}
+
+// affected returns the records of a domain that op modifies or deletes.
+func affected(records []Record, op Operation) []Record {
+	var found []Record
+	for _, record := range records {
+		if matches(record, op) {
+			found = append(found, record)
+		}
+	}
+
+	return found
+}
+
+// applied returns what the records a change affected look like after it was
+// made, with id the id of a record it created.
+func applied(op Operation, before []Record, id RecordID) []Record {
+	switch op.Kind {
+	case OperationCreate:
+		record := op.Record
+		record.Id = id
+		record.Name = fqdn(op.Record.Name, op.Domain)
+		return []Record{record}
+	case OperationModify:
+		var records []Record
+		for _, record := range before {
+			records = append(records, modified(record, op))
+		}
+		return records
+	default:
+		return nil
+	}
+}
+
+// change returns the records of a domain as op leaves them, with id the id of
+// a record it creates.
+func change(records []Record, op Operation, id RecordID) []Record {
+	var changed []Record
+	for _, record := range records {
+		switch {
+		case !matches(record, op):
+			changed = append(changed, record)
+		case op.Kind == OperationModify:
+			changed = append(changed, modified(record, op))
+		}
+	}
+
+	if op.Kind == OperationCreate {
+		changed = append(changed, applied(op, nil, id)...)
+	}
+
+	return changed
+}
+
+// modified returns a record as a modify operation leaves it.
+func modified(record Record, op Operation) Record {
+	record.Content = op.Record.Content
+	record.TTL = op.Record.TTL
+	record.Priority = op.Record.Priority
+	if op.Record.Id != "" {
+		record.Name = fqdn(op.Record.Name, op.Domain)
+		record.Type = op.Record.Type
+	}
+
+	return record
+}
+
+// fqdn joins a subdomain onto a domain, the way Porkbun names records.
+func fqdn(subdomain, domain string) string {
+	if subdomain == "" || subdomain == "@" {
+		return domain
+	}
+
+	return subdomain + "." + domain
+}
+
+// relativeName strips the domain from a record name returned by Porkbun.
+func relativeName(name, domain string) string {
+	if name == domain {
+		return ""
+	}
+
+	return strings.TrimSuffix(name, "."+domain)
+}
@@ -0,0 +1,302 @@
+package porkbun_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestChangeSet(t *testing.T) {
+	ctx := context.TODO()
+
+	seed := func(server *porkbuntest.Server) {
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "api", Type: "A", Content: "10.0.0.1"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "old", Type: "A", Content: "10.0.0.2"})
+	}
+
+	t.Run("applied", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		seed(server)
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Modify("example.com", porkbun.Record{Name: "api", Type: "A", Content: "10.0.0.4"}).
+			Delete("example.com", porkbun.Record{Name: "old", Type: "A"}).
+			Apply(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		for _, result := range report.Results {
+			if result.State != porkbun.ChangeApplied {
+				t.Errorf("%s: got %s, want %s", result.Operation, result.State, porkbun.ChangeApplied)
+			}
+		}
+
+		if after := report.Results[1].After; len(after) != 1 || after[0].Content != "10.0.0.4" {
+			t.Errorf("got %+v, want the modified record", after)
+		}
+
+		records := server.Backend.Records("example.com")
+		if len(records) != 2 || records[0].Content != "10.0.0.4" || records[1].Name != "www.example.com" {
+			t.Errorf("got %+v, want api modified, old deleted and www created", records)
+		}
+	})
+
+	t.Run("rolled back", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		seed(server)
+		before := server.Backend.Records("example.com")
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Modify("example.com", porkbun.Record{Id: before[0].Id, Name: "api", Type: "A", Content: "10.0.0.4"}).
+			Delete("example.com", porkbun.Record{Id: before[1].Id}).
+			Create("example.com", porkbun.Record{Name: "www", Type: "CNAME", Content: "example.net"}).
+			Create("example.com", porkbun.Record{Name: "mail", Type: "A", Content: "10.0.0.5"}).
+			Apply(ctx)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		want := []porkbun.ChangeState{
+			porkbun.ChangeRolledBack,
+			porkbun.ChangeRolledBack,
+			porkbun.ChangeRolledBack,
+			porkbun.ChangeFailed,
+			porkbun.ChangeSkipped,
+		}
+		for i, result := range report.Results {
+			if result.State != want[i] {
+				t.Errorf("%s: got %s, want %s", result.Operation, result.State, want[i])
+			}
+		}
+
+		records := server.Backend.Records("example.com")
+		if len(records) != 2 {
+			t.Fatalf("got %d records, want 2", len(records))
+		}
+
+		if records[0] != before[0] {
+			t.Errorf("got %+v, want %+v", records[0], before[0])
+		}
+
+		recreated := report.Results[2].After
+		if len(recreated) != 1 || recreated[0] != records[1] || recreated[0].Id == before[1].Id {
+			t.Errorf("got %+v, want old recreated with a new id as %+v", recreated, records[1])
+		}
+	})
+
+	t.Run("rollback failed", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Endpoint: "dns/delete",
+			Fault:    porkbuntest.ServerError(http.StatusServiceUnavailable),
+		}))
+		defer server.Close()
+		seed(server)
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Create("example.com", porkbun.Record{Name: "www", Type: "CNAME", Content: "example.net"}).
+			Apply(ctx)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		if report.Results[0].State != porkbun.ChangeRollbackFailed {
+			t.Errorf("got %s, want %s", report.Results[0].State, porkbun.ChangeRollbackFailed)
+		}
+
+		if after := report.Results[0].After; len(after) != 1 || after[0].Name != "www.example.com" {
+			t.Errorf("got %+v, want the created record left behind", after)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Endpoint: "dns/create",
+			Start:    2,
+			Count:    1,
+			Fault:    porkbuntest.Latency(time.Minute),
+		}))
+		defer server.Close()
+		seed(server)
+		before := server.Backend.Records("example.com")
+
+		ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Delete("example.com", porkbun.Record{Name: "old", Type: "A"}).
+			Create("example.com", porkbun.Record{Name: "mail", Type: "A", Content: "10.0.0.5"}).
+			Apply(ctx)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		want := []porkbun.ChangeState{
+			porkbun.ChangeRolledBack,
+			porkbun.ChangeRolledBack,
+			porkbun.ChangeFailed,
+		}
+		for i, result := range report.Results {
+			if result.State != want[i] {
+				t.Errorf("%s: got %s, want %s", result.Operation, result.State, want[i])
+			}
+		}
+
+		records := server.Backend.Records("example.com")
+		if len(records) != len(before) {
+			t.Fatalf("got %+v, want the records as they were", records)
+		}
+		for i := range records {
+			if records[i].Name != before[i].Name || records[i].Content != before[i].Content {
+				t.Errorf("got %+v, want %+v", records[i], before[i])
+			}
+		}
+	})
+
+	t.Run("missing record", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		seed(server)
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Delete("example.com", porkbun.Record{Name: "mail", Type: "A"}).
+			Create("example.com", porkbun.Record{Name: "api2", Type: "A", Content: "10.0.0.4"}).
+			Apply(ctx)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		for _, result := range report.Results {
+			if result.State != porkbun.ChangeSkipped {
+				t.Errorf("%s: got %q, want %s", result.Operation, result.State, porkbun.ChangeSkipped)
+			}
+		}
+
+		if records := server.Backend.Records("example.com"); len(records) != 2 {
+			t.Errorf("got %d records, want nothing changed", len(records))
+		}
+	})
+
+	t.Run("failed but made", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Endpoint: "dns/create",
+			Start:    2,
+			Count:    1,
+			Fault:    porkbuntest.Fault{DropResponse: true},
+		}))
+		defer server.Close()
+		seed(server)
+		before := server.Backend.Records("example.com")
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Create("example.com", porkbun.Record{Name: "mail", Type: "A", Content: "10.0.0.5"}).
+			Apply(ctx)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		for _, result := range report.Results {
+			if result.State != porkbun.ChangeRolledBack {
+				t.Errorf("%s: got %s, want %s", result.Operation, result.State, porkbun.ChangeRolledBack)
+			}
+		}
+
+		if records := server.Backend.Records("example.com"); !reflect.DeepEqual(records, before) {
+			t.Errorf("got %+v, want %+v", records, before)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(
+			porkbuntest.Rule{
+				Endpoint: "dns/create",
+				Start:    2,
+				Fault:    porkbuntest.ServerError(http.StatusServiceUnavailable),
+			},
+			porkbuntest.Rule{
+				Endpoint: "dns/retrieve",
+				Start:    2,
+				Fault:    porkbuntest.ServerError(http.StatusServiceUnavailable),
+			},
+		))
+		defer server.Close()
+		seed(server)
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Create("example.com", porkbun.Record{Name: "mail", Type: "A", Content: "10.0.0.5"}).
+			Apply(ctx)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		want := []porkbun.ChangeState{porkbun.ChangeRolledBack, porkbun.ChangeUnknown}
+		for i, result := range report.Results {
+			if result.State != want[i] {
+				t.Errorf("%s: got %s, want %s", result.Operation, result.State, want[i])
+			}
+		}
+	})
+
+	t.Run("change a record made earlier", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		seed(server)
+
+		_, err := newTestClient(t, server).NewChangeSet().
+			Create("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"}).
+			Modify("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.4"}).
+			Create("example.com", porkbun.Record{Name: "mail", Type: "A", Content: "10.0.0.5"}).
+			Delete("example.com", porkbun.Record{Name: "mail", Type: "A"}).
+			Apply(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		records := server.Backend.Records("example.com")
+		if len(records) != 3 || records[2].Name != "www.example.com" || records[2].Content != "10.0.0.4" {
+			t.Errorf("got %+v, want www created and modified, and mail gone", records)
+		}
+	})
+
+	t.Run("modified then deleted", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		seed(server)
+		before := server.Backend.Records("example.com")
+
+		report, err := newTestClient(t, server).NewChangeSet().
+			Modify("example.com", porkbun.Record{Id: before[0].Id, Name: "api", Type: "A", Content: "10.0.0.4"}).
+			Delete("example.com", porkbun.Record{Id: before[0].Id}).
+			Create("example.com", porkbun.Record{Name: "old", Type: "CNAME", Content: "example.net"}).
+			Apply(ctx)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+
+		want := []porkbun.ChangeState{porkbun.ChangeRolledBack, porkbun.ChangeRolledBack, porkbun.ChangeFailed}
+		for i, result := range report.Results {
+			if result.State != want[i] {
+				t.Errorf("%s: got %s, want %s", result.Operation, result.State, want[i])
+			}
+		}
+
+		records := server.Backend.Records("example.com")
+		if len(records) != 2 || records[1].Name != "api.example.com" || records[1].Content != "10.0.0.1" {
+			t.Errorf("got %+v, want api restored with its old content", records)
+		}
+	})
+}
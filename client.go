@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 )
 
 const (
@@ -43,6 +46,11 @@ type Client struct {
 	secretKey string
 	baseUrl   string
 	client    HttpClient
+
+	skipValidation bool
+
+	zoneCacheTTL time.Duration
+	zoneCache    *zoneCache
 }
 
 // NewClient creates a new porkbun client.
@@ -53,6 +61,9 @@ func NewClient(options ...Option) (*Client, error) {
 		secretKey: os.Getenv(PORKBUN_SECRET_KEY),
 		baseUrl:   "https://porkbun.com",
 		client:    &http.Client{},
+
+		zoneCacheTTL: DefaultZoneCacheTTL,
+		zoneCache:    &zoneCache{},
 	}
 
 	for _, option := range options {
@@ -93,6 +104,37 @@ func WithHttpClient(httpClient HttpClient) Option {
 	}
 }
 
+// WithoutValidation stops CreateDnsRecord and ModifyDnsRecord from checking
+// records with Record.Validate, leaving it all to Porkbun.
+func WithoutValidation() Option {
+	return func(c *Client) error {
+		c.skipValidation = true
+		return nil
+	}
+}
+
+// prepare returns a copy of a record ready to be sent. It is checked with
+// Validate, unless validation is off, then its names are converted with
+// ToASCII and long TXT content is split with SplitText.
+func (c *Client) prepare(record *Record) (*Record, error) {
+	if !c.skipValidation {
+		if err := record.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	prepared := *record
+	if err := recordToASCII(&prepared); err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(prepared.Type, "TXT") && !strings.HasPrefix(strings.TrimSpace(prepared.Content), `"`) {
+		prepared.Content = SplitText(prepared.Content)
+	}
+
+	return &prepared, nil
+}
+
 func (c *Client) withAuthentication(body []byte) ([]byte, error) {
 	if c.apiKey == "" {
 		return nil, MissingAccessKeyError{Key: PORKBUN_API_KEY}
@@ -130,7 +172,8 @@ func (c *Client) withAuthentication(body []byte) ([]byte, error) {
 }
 
 func (c *Client) do(ctx context.Context, endpoint string, body []byte) (*http.Response, error) {
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		fmt.Sprintf("%s/%s", c.baseUrl, endpoint),
 		bytes.NewReader(body),
@@ -140,3 +183,21 @@ func (c *Client) do(ctx context.Context, endpoint string, body []byte) (*http.Re
 	}
 	return c.client.Do(req)
 }
+
+// checkResponse returns an *ApiError holding the response body when the status
+// code is outside the 2xx range.
+func checkResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	return &ApiError{
+		Code: res.StatusCode,
+		Body: string(body),
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andrew-womeldorf/porkbun-go/audit"
+	"github.com/spf13/cobra"
+)
+
+func initAuditCmd() {
+	auditCmd.AddCommand(auditEmailCmd)
+
+	flags := auditEmailCmd.Flags()
+	flags.String("format", "text", "output format: text or json")
+	flags.String("min-severity", "info", "leave out findings less severe than this")
+	flags.String("fail-on", "", "exit with 1 if there is a finding at least this severe")
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check domains for weak spots",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var auditEmailCmd = &cobra.Command{
+	Use:   "email [DOMAIN...]",
+	Short: "Check how well domains are protected against spoofed mail",
+	Long: `Check how well domains are protected against spoofed mail.
+
+The records of each domain are read from Porkbun, and checked for a missing or
+weak SPF record, a missing DMARC record or one with p=none, DKIM selectors
+with short keys, MX records pointing at a CNAME, and a missing MTA-STS record.
+
+A domain without MX records is taken to be parked, and should reject all mail
+claiming to be from it.
+
+Without DOMAIN, every domain in the account is checked. A subdomain given as
+DOMAIN checks the domain in the account it belongs to.
+
+Severities are info, low, medium, high and critical.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		flags := cmd.Flags()
+
+		format, err := flags.GetString("format")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting format var, %w", err))
+		}
+		if format != "text" && format != "json" {
+			log.Fatalf("unknown format %q, want text or json", format)
+		}
+
+		minSeverityName, err := flags.GetString("min-severity")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting min-severity var, %w", err))
+		}
+		minSeverity, err := audit.ParseSeverity(minSeverityName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		failOnName, err := flags.GetString("fail-on")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting fail-on var, %w", err))
+		}
+		failOn := audit.Critical + 1
+		if failOnName != "" {
+			if failOn, err = audit.ParseSeverity(failOnName); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		var domains []string
+		for _, arg := range args {
+			_, dom := resolveZone(ctx, client, arg)
+			domains = append(domains, dom)
+		}
+		if len(domains) == 0 {
+			res, err := client.ListDomains(ctx)
+			if err != nil {
+				log.Fatal(fmt.Errorf("err listing domains, %w", err))
+			}
+			for _, domain := range res.Domains {
+				domains = append(domains, domain.Domain)
+			}
+		}
+
+		findings := []audit.Finding{}
+		failed := false
+		for _, domain := range domains {
+			res, err := client.ListDnsRecords(ctx, domain, "", "")
+			if err != nil {
+				log.Fatal(fmt.Errorf("err listing dns records of %q, %w", domain, err))
+			}
+
+			for _, finding := range audit.Email(domain, res.Records) {
+				if finding.Severity >= failOn {
+					failed = true
+				}
+				if finding.Severity >= minSeverity {
+					findings = append(findings, finding)
+				}
+			}
+		}
+
+		for i := range findings {
+			findings[i].Domain = displayName(findings[i].Domain)
+			findings[i].Name = displayName(findings[i].Name)
+		}
+		for i := range domains {
+			domains[i] = displayName(domains[i])
+		}
+
+		if format == "json" {
+			printJSON(findings)
+		} else {
+			printFindings(domains, findings)
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// printFindings writes findings as a table for each domain.
+func printFindings(domains []string, findings []audit.Finding) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	for i, domain := range domains {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, domain)
+
+		found := false
+		for _, finding := range findings {
+			if !strings.EqualFold(finding.Domain, domain) {
+				continue
+			}
+			found = true
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", strings.ToUpper(finding.Severity.String()), finding.Check, finding.Name, finding.Message)
+		}
+
+		if !found {
+			fmt.Fprintln(w, "  no findings")
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/dkim"
+	"github.com/spf13/cobra"
+)
+
+func initDkimCmd() {
+	dkimCmd.AddCommand(dkimRotateCmd)
+
+	flags := dkimRotateCmd.Flags()
+	flags.String("selector", "", "selector of the new key")
+	flags.String("algorithm", string(dkim.RSA), "algorithm of the new key, rsa or ed25519")
+	flags.String("key-file", "", "file to write the private key to. defaults to SELECTOR.DOMAIN.pem")
+	flags.String("retire", "", "selector of the old key, to remove after the grace period")
+	flags.Duration("grace", 7*24*time.Hour, "how long to keep the old selector published")
+	flags.Uint32("ttl", 600, "time to live for the record, in seconds")
+	dkimRotateCmd.MarkFlagRequired("selector")
+}
+
+var dkimCmd = &cobra.Command{
+	Use:   "dkim",
+	Short: "Manage DKIM keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// dkimRotation is the output of dkim rotate.
+type dkimRotation struct {
+	Selector string           `json:"selector"`
+	KeyFile  string           `json:"keyFile"`
+	Id       porkbun.RecordID `json:"id"`
+
+	// When the selector passed to --retire may be removed.
+	RetireAfter *time.Time `json:"retireAfter,omitempty"`
+
+	// The selectors removed because their grace period was over.
+	Removed []string `json:"removed"`
+}
+
+var dkimRotateCmd = &cobra.Command{
+	Use:   "rotate DOMAIN",
+	Short: "Publish a new DKIM key, and retire the old one",
+	Long: `Publish a new DKIM key, and retire the old one.
+
+A new key is generated, its private key written to --key-file, and its public
+key published at SELECTOR._domainkey under DOMAIN. Configure the mail server to
+sign with the new key once the record has propagated.
+
+With --retire, the old selector is marked to be removed once the grace period
+is over, so mail signed with it can still be checked on its way. The mark is
+kept in the n= tag of its record. Every run removes the selectors whose grace
+period is over, so run it again later, with any selector, to clean up.
+
+DOMAIN is the complete domain, such as 'example.com'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		flags := cmd.Flags()
+
+		selector, err := flags.GetString("selector")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting selector var, %w", err))
+		}
+
+		algorithm, err := flags.GetString("algorithm")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting algorithm var, %w", err))
+		}
+
+		keyFile, err := flags.GetString("key-file")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting key-file var, %w", err))
+		}
+
+		retire, err := flags.GetString("retire")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting retire var, %w", err))
+		}
+
+		grace, err := flags.GetDuration("grace")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting grace var, %w", err))
+		}
+
+		ttl, err := flags.GetUint32("ttl")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting ttl var, %w", err))
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
+		if keyFile == "" {
+			keyFile = selector + "." + args[0] + ".pem"
+		}
+
+		existing, err := client.ListDnsRecords(ctx, dom, dkim.RecordName(selector, sub), "TXT")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err looking up selector %q, %w", selector, err))
+		}
+		if len(existing.Records) > 0 {
+			log.Fatalf("selector %q is already published; pick a new one", selector)
+		}
+
+		key, err := dkim.GenerateKey(dkim.Algorithm(algorithm))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		record, err := key.Record(selector, sub)
+		if err != nil {
+			log.Fatal(err)
+		}
+		record.TTL = porkbun.TTL(ttl)
+
+		privatePEM, err := key.PrivateKeyPEM()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// The key is written before it is published, so a published key
+		// always has its private half somewhere.
+		if err := writeNewFile(keyFile, privatePEM); err != nil {
+			log.Fatal(fmt.Errorf("err writing private key, %w", err))
+		}
+
+		slog.Debug("Sending create request", "params", record, "domain", dom)
+
+		res, err := client.CreateDnsRecord(ctx, dom, &record)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err publishing selector %q, %w", selector, err))
+		}
+
+		rotation := dkimRotation{Selector: selector, KeyFile: keyFile, Id: res.Id, Removed: []string{}}
+
+		if retire != "" {
+			old, err := client.ListDnsRecords(ctx, dom, dkim.RecordName(retire, sub), "TXT")
+			if err != nil {
+				log.Fatal(fmt.Errorf("err looking up selector %q, %w", retire, err))
+			}
+			if len(old.Records) != 1 {
+				log.Fatalf("found %d records for selector %q, want 1", len(old.Records), retire)
+			}
+
+			after := time.Now().Add(grace).UTC().Truncate(time.Second)
+			marked := old.Records[0]
+			if marked.Content, err = dkim.Retire(marked, after); err != nil {
+				log.Fatal(err)
+			}
+			marked.Name = dkim.RecordName(retire, sub)
+
+			slog.Debug("Sending modify request", "params", marked, "domain", dom)
+
+			if _, err := client.ModifyDnsRecord(ctx, dom, &marked); err != nil {
+				log.Fatal(fmt.Errorf("err retiring selector %q, %w", retire, err))
+			}
+			rotation.RetireAfter = &after
+		}
+
+		all, err := client.ListDnsRecords(ctx, dom, "", "")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err listing dns records, %w", err))
+		}
+
+		for _, expired := range dkim.Expired(all.Records, time.Now()) {
+			slog.Debug("Sending delete request", "id", expired.Id, "domain", dom)
+
+			if _, err := client.DeleteDnsRecordById(ctx, dom, expired.Id); err != nil {
+				log.Fatal(fmt.Errorf("err removing %q, %w", expired.Name, err))
+			}
+
+			removed, _ := dkim.Selector(expired.Name)
+			rotation.Removed = append(rotation.Removed, removed)
+		}
+
+		printJSON(rotation)
+	},
+}
+
+// writeNewFile writes a file only the user can read, failing if it exists.
+func writeNewFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
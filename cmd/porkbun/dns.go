@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
-	"strings"
 
 	"github.com/andrew-womeldorf/porkbun-go"
 	"github.com/spf13/cobra"
@@ -18,15 +17,21 @@ func initDnsCmd() {
 	dnsCmd.AddCommand(dnsGetCmd)
 	dnsCmd.AddCommand(dnsEditCmd)
 	dnsCmd.AddCommand(dnsDeleteCmd)
+	dnsCmd.AddCommand(dnsEnsureCmd)
 
 	dnsCreateFlags := dnsCreateCmd.Flags()
-	dnsCreateFlags.String("ttl", "600", "time to live for the record")
-	dnsCreateFlags.String("priority", "", "priority of the record for those that support it")
+	dnsCreateFlags.Uint32("ttl", 600, "time to live for the record, in seconds")
+	dnsCreateFlags.Int("priority", 0, "priority of the record for those that support it")
 
 	dnsEditFlags := dnsEditCmd.Flags()
 	dnsEditFlags.String("id", "", "id of the record to change. leave empty to lookup by subdomain and type")
-	dnsEditFlags.String("ttl", "600", "time to live for the record")
-	dnsEditFlags.String("priority", "", "priority of the record for those that support it")
+	dnsEditFlags.Uint32("ttl", 600, "time to live for the record, in seconds")
+	dnsEditFlags.Int("priority", 0, "priority of the record for those that support it")
+	dnsEditFlags.String("expect-content", "", "only modify the record if it still has this content")
+
+	dnsEnsureFlags := dnsEnsureCmd.Flags()
+	dnsEnsureFlags.Uint32("ttl", 600, "time to live for the record, in seconds")
+	dnsEnsureFlags.Int("priority", 0, "priority of the record for those that support it")
 
 	dnsDeleteFlags := dnsDeleteCmd.Flags()
 	dnsDeleteFlags.String("id", "", "id of the record to delete")
@@ -47,38 +52,36 @@ var dnsCreateCmd = &cobra.Command{
 	Long: `Create a new DNS entry.
 
 DOMAIN is the complete domain, such as 'foo.example.com', where 'foo' is the
-record entry on the 'example.com' domain.
+record entry on the 'example.com' domain, the longest domain in the account
+DOMAIN ends in.
 TYPE is the type of record being created, such as A, AAAA, TXT, MX...
 CONTENT is the answer for the record.`,
 	Args: cobra.ExactArgs(3),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
-		priority, err := cmd.Flags().GetString("priority")
+		priority, err := cmd.Flags().GetInt("priority")
 		if err != nil {
 			log.Fatal(fmt.Errorf("err getting priority var, %w", err))
 		}
 
-		ttl, err := cmd.Flags().GetString("ttl")
+		ttl, err := cmd.Flags().GetUint32("ttl")
 		if err != nil {
 			log.Fatal(fmt.Errorf("err getting ttl var, %v", err))
 		}
 
-		sub, dom, err := ParseDomain(args[0])
+		client, err := newClient()
 		if err != nil {
-			log.Fatal(fmt.Errorf("err parsing domain, %v", err))
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
 		}
 
+		sub, dom := resolveZone(ctx, client, args[0])
+
 		req := &porkbun.Record{
 			Name:     sub,
 			Type:     args[1],
 			Content:  args[2],
-			TTL:      ttl,
-			Priority: priority,
-		}
-
-		client, err := porkbun.NewClient()
-		if err != nil {
-			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+			TTL:      porkbun.TTL(ttl),
+			Priority: porkbun.Priority(priority),
 		}
 
 		slog.Debug("Sending create request", "params", req, "domain", dom)
@@ -103,22 +106,20 @@ var dnsListCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
 
-		_, dom, err := ParseDomain(args[0])
-		if err != nil {
-			log.Fatal(fmt.Errorf("err parsing domain, %v", err))
-		}
-
-		client, err := porkbun.NewClient()
+		client, err := newClient()
 		if err != nil {
 			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
 		}
 
+		_, dom := resolveZone(ctx, client, args[0])
+
 		slog.Debug("Sending list request", "domain", dom)
 
 		res, err := client.ListDnsRecords(ctx, dom, "", "")
 		if err != nil {
 			log.Fatal(fmt.Errorf("err listing dns records, %w", err))
 		}
+		res.Records = displayRecords(res.Records)
 
 		resBytes, err := json.Marshal(res)
 		if err != nil {
@@ -139,22 +140,20 @@ find all entries for a type, but finds a single record.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
 
-		sub, dom, err := ParseDomain(args[0])
-		if err != nil {
-			log.Fatal(fmt.Errorf("err parsing domain, %v", err))
-		}
-
-		client, err := porkbun.NewClient()
+		client, err := newClient()
 		if err != nil {
 			log.Fatal(fmt.Errorf("err creating porkbun client, %v", err))
 		}
 
+		sub, dom := resolveZone(ctx, client, args[0])
+
 		slog.Debug("Sending list request", "domain", dom, "sub", sub, "type", args[1])
 
 		res, err := client.ListDnsRecords(ctx, dom, sub, args[1])
 		if err != nil {
 			log.Fatal(fmt.Errorf("err listing dns records, %v", err))
 		}
+		res.Records = displayRecords(res.Records)
 
 		resBytes, err := json.Marshal(res)
 		if err != nil {
@@ -172,19 +171,24 @@ var dnsEditCmd = &cobra.Command{
 If the --id flag is provided, then the record will be found by id and modified.
 Otherwise, the record will be lookedup by the domain and record type.
 
+If the --expect-content flag is provided, the record is read first, and only
+modified if its content still matches. This stops two scripts from silently
+overwriting each other's changes.
+
 DOMAIN is the complete domain, such as 'foo.example.com', where 'foo' is the
-record entry on the 'example.com' domain.
+record entry on the 'example.com' domain, the longest domain in the account
+DOMAIN ends in.
 TYPE is the type of record being created, such as A, AAAA, TXT, MX...
 CONTENT is the answer for the record.`,
 	Args: cobra.ExactArgs(3),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
-		priority, err := cmd.Flags().GetString("priority")
+		priority, err := cmd.Flags().GetInt("priority")
 		if err != nil {
 			log.Fatal(fmt.Errorf("err getting priority var, %w", err))
 		}
 
-		ttl, err := cmd.Flags().GetString("ttl")
+		ttl, err := cmd.Flags().GetUint32("ttl")
 		if err != nil {
 			log.Fatal(fmt.Errorf("err getting ttl var, %v", err))
 		}
@@ -194,28 +198,44 @@ CONTENT is the answer for the record.`,
 			log.Fatal(fmt.Errorf("err getting int var, %v", err))
 		}
 
-		sub, dom, err := ParseDomain(args[0])
+		expectContent, err := cmd.Flags().GetString("expect-content")
 		if err != nil {
-			log.Fatal(fmt.Errorf("err parsing domain, %v", err))
+			log.Fatal(fmt.Errorf("err getting expect-content var, %w", err))
 		}
 
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
 		req := &porkbun.Record{
-			Id:       id,
+			Id:       porkbun.RecordID(id),
 			Name:     sub,
 			Type:     args[1],
 			Content:  args[2],
-			TTL:      ttl,
-			Priority: priority,
+			TTL:      porkbun.TTL(ttl),
+			Priority: porkbun.Priority(priority),
 		}
 
-		client, err := porkbun.NewClient()
-		if err != nil {
-			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
-		}
+		var res *porkbun.StatusResponse
+		if expectContent != "" {
+			expected := &porkbun.Record{
+				Id:      porkbun.RecordID(id),
+				Name:    sub,
+				Type:    args[1],
+				Content: expectContent,
+			}
+
+			slog.Debug("Sending conditional modify request", "params", req, "expected", expected, "domain", dom)
 
-		slog.Debug("Sending modify request", "params", req, "domain", dom)
+			res, err = client.ModifyDnsRecordIf(ctx, dom, expected, req)
+		} else {
+			slog.Debug("Sending modify request", "params", req, "domain", dom)
 
-		res, err := client.ModifyDnsRecord(ctx, dom, req)
+			res, err = client.ModifyDnsRecord(ctx, dom, req)
+		}
 		if err != nil {
 			log.Fatal(fmt.Errorf("err modifying dns record, %w", err))
 		}
@@ -238,7 +258,8 @@ If the --id flag is empty, and the --type flag is provided, then the record
 will be looked up by subdomain and the provided type.
 
 DOMAIN is the complete domain, such as 'foo.example.com', where 'foo' is the
-record entry on the 'example.com' domain.`,
+record entry on the 'example.com' domain, the longest domain in the account
+DOMAIN ends in.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
@@ -253,21 +274,18 @@ record entry on the 'example.com' domain.`,
 			log.Fatal(fmt.Errorf("err getting int var, %v", err))
 		}
 
-		sub, dom, err := ParseDomain(args[0])
-		if err != nil {
-			log.Fatal(fmt.Errorf("err parsing domain, %v", err))
-		}
-
-		client, err := porkbun.NewClient()
+		client, err := newClient()
 		if err != nil {
 			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
 		}
 
+		sub, dom := resolveZone(ctx, client, args[0])
+
 		slog.Debug("Sending delete request", "id", id, "domain", dom, "subdomain", sub, "recordType", recordType)
 
 		var res interface{}
 		if id != "" {
-			res, err = client.DeleteDnsRecordById(ctx, dom, id)
+			res, err = client.DeleteDnsRecordById(ctx, dom, porkbun.RecordID(id))
 		} else {
 			res, err = client.DeleteDnsRecordByLookup(ctx, dom, sub, recordType)
 		}
@@ -283,30 +301,60 @@ record entry on the 'example.com' domain.`,
 	},
 }
 
-// ParseDomain takes a full domain as an input, and return the subdomain, the
-// domain, and an error.
-//
-// "example.com" -> "", "example.com", nil
-// "foo.example.com" -> "foo", "example.com", nil
-// "*.example.com" -> "*", "example.com", nil
-// "foo.bar.example.com" -> "foo.bar", "example.com", nil
-func ParseDomain(domain string) (string, string, error) {
-	// Split on "."
-	parts := strings.Split(domain, ".")
-
-	// Handle error cases
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("invalid domain %s", domain)
-	}
-
-	// The domain is the last two parts
-	dom := strings.Join(parts[len(parts)-2:], ".")
-
-	// The subdomain is everything before the last two parts
-	var sub string
-	if len(parts) > 2 {
-		sub = strings.Join(parts[:len(parts)-2], ".")
-	}
-
-	return sub, dom, nil
+var dnsEnsureCmd = &cobra.Command{
+	Use:   "ensure DOMAIN TYPE CONTENT",
+	Short: "Create or modify a DNS entry so it matches",
+	Long: `Create or modify a DNS entry so it matches.
+
+The record is looked up by subdomain and type. If a record with the same
+content exists, its TTL and priority are updated if needed. If there is a
+single record with other content, it is modified. Otherwise, the record is
+created. Running the same command twice makes no further changes.
+
+DOMAIN is the complete domain, such as 'foo.example.com', where 'foo' is the
+record entry on the 'example.com' domain, the longest domain in the account
+DOMAIN ends in.
+TYPE is the type of record, such as A, AAAA, TXT, MX...
+CONTENT is the answer for the record.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		priority, err := cmd.Flags().GetInt("priority")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting priority var, %w", err))
+		}
+
+		ttl, err := cmd.Flags().GetUint32("ttl")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting ttl var, %w", err))
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
+		req := &porkbun.Record{
+			Name:     sub,
+			Type:     args[1],
+			Content:  args[2],
+			TTL:      porkbun.TTL(ttl),
+			Priority: porkbun.Priority(priority),
+		}
+
+		slog.Debug("Sending ensure request", "params", req, "domain", dom)
+
+		res, err := client.EnsureDnsRecord(ctx, dom, req)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err ensuring dns record, %w", err))
+		}
+
+		resBytes, err := json.Marshal(res)
+		if err != nil {
+			log.Fatal(fmt.Errorf("error marshaling response to JSON, %w", err))
+		}
+		fmt.Println(string(resBytes))
+	},
 }
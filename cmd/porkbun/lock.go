@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go/lock"
+	"github.com/spf13/cobra"
+)
+
+// The exit code of lock run when the lease is lost and COMMAND is killed, as
+// sysexits.h EX_TEMPFAIL.
+const lockLostExitCode = 75
+
+func initLockCmd() {
+	lockCmd.AddCommand(lockRunCmd)
+
+	lockRunFlags := lockRunCmd.Flags()
+	lockRunFlags.Duration("lease", lock.DefaultLeaseDuration, "how long the lock is held without being renewed")
+	lockRunFlags.Duration("wait", 0, "how long to wait for the lock when someone else holds it")
+	lockRunFlags.String("owner", "", "name of the holder stored in the lock record. defaults to the hostname and pid")
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Take turns with other machines using a lock stored in DNS",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var lockRunCmd = &cobra.Command{
+	Use:   "run NAME -- COMMAND [ARGS...]",
+	Short: "Run a command while holding a lock",
+	Long: `Run a command while holding a lock.
+
+NAME is the complete name of the lock record, such as '_lock.example.com'.
+The lock is a TXT record there, holding the owner, expiry and fencing token.
+
+The lease is renewed in the background while COMMAND runs, and released when
+it exits. If the lease is lost, COMMAND is killed. The fencing token is passed
+to COMMAND in the PORKBUN_LOCK_TOKEN environment variable.
+
+The exit code is the exit code of COMMAND, or 75 if the lease was lost and
+COMMAND was killed.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		leaseDuration, err := cmd.Flags().GetDuration("lease")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting lease var, %w", err))
+		}
+
+		wait, err := cmd.Flags().GetDuration("wait")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting wait var, %w", err))
+		}
+
+		owner, err := cmd.Flags().GetString("owner")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting owner var, %w", err))
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
+		options := []lock.Option{lock.WithName(sub), lock.WithLeaseDuration(leaseDuration)}
+		if owner != "" {
+			options = append(options, lock.WithOwner(owner))
+		}
+		l := lock.New(client, dom, options...)
+
+		slog.Debug("Acquiring lock", "domain", dom, "name", sub, "wait", wait)
+
+		var lease *lock.Lease
+		if wait > 0 {
+			waitCtx, cancel := context.WithTimeout(ctx, wait)
+			lease, err = l.Wait(waitCtx, min(wait, 10*time.Second))
+			cancel()
+		} else {
+			lease, err = l.Acquire(ctx)
+		}
+		if err != nil {
+			log.Fatal(fmt.Errorf("err acquiring lock, %w", err))
+		}
+
+		slog.Debug("Acquired lock", "token", lease.Token, "expires", lease.Expires)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var lost atomic.Bool
+
+		go func() {
+			ticker := time.NewTicker(leaseDuration / 3)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-runCtx.Done():
+					return
+				case <-ticker.C:
+				}
+
+				err := lease.Renew(runCtx)
+				if errors.Is(err, lock.ErrLeaseLost) {
+					slog.Error("Lost lock, stopping command", "token", lease.Token)
+					lost.Store(true)
+					cancel()
+					return
+				}
+				if err != nil && runCtx.Err() == nil {
+					slog.Warn("Could not renew lease", "err", err)
+				}
+			}
+		}()
+
+		child := exec.CommandContext(runCtx, args[1], args[2:]...)
+		child.Stdin = os.Stdin
+		child.Stdout = os.Stdout
+		child.Stderr = os.Stderr
+		child.Env = append(os.Environ(), "PORKBUN_LOCK_TOKEN="+strconv.FormatInt(lease.Token, 10))
+
+		runErr := child.Run()
+		cancel()
+
+		if err := lease.Release(ctx); err != nil {
+			slog.Warn("Could not release lock", "err", err)
+		}
+
+		if lost.Load() {
+			slog.Error("Command was stopped, the lock was lost", "err", runErr)
+			os.Exit(lockLostExitCode)
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		if runErr != nil {
+			log.Fatal(fmt.Errorf("err running command, %w", runErr))
+		}
+	},
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/mailpolicy"
+	"github.com/spf13/cobra"
+)
+
+func initMailPolicyCmd() {
+	flags := mailPolicyCmd.Flags()
+	flags.String("dmarc", "", "dmarc policy to publish: none, quarantine or reject")
+	flags.String("dmarc-subdomains", "", "dmarc policy for subdomains, if different")
+	flags.Int("dmarc-pct", -1, "percent of failing mail the dmarc policy applies to. defaults to all of it")
+	flags.StringSlice("dmarc-rua", nil, "mailto: or https: addresses for dmarc aggregate reports")
+	flags.StringSlice("dmarc-ruf", nil, "mailto: or https: addresses for dmarc failure reports")
+	flags.String("dmarc-adkim", "", "dkim alignment: r for relaxed or s for strict")
+	flags.String("dmarc-aspf", "", "spf alignment: r for relaxed or s for strict")
+	flags.String("mta-sts", "", "mta-sts mode to publish: enforce, testing or none")
+	flags.StringSlice("mta-sts-mx", nil, "mail servers for the mta-sts policy. defaults to the MX records of DOMAIN")
+	flags.Duration("mta-sts-max-age", 7*24*time.Hour, "how long senders may cache the mta-sts policy")
+	flags.String("mta-sts-policy-file", "mta-sts.txt", "file to write the mta-sts policy to")
+	flags.Bool("mta-sts-write-only", false, "write the mta-sts policy file without publishing the _mta-sts record")
+	flags.StringSlice("tls-rpt", nil, "mailto: or https: addresses for tls-rpt reports")
+	flags.Uint32("ttl", 600, "time to live for the records, in seconds")
+}
+
+// mailPolicyWritten is the action of an _mta-sts record left unpublished by
+// --mta-sts-write-only, once its policy file is written.
+const mailPolicyWritten porkbun.EnsureAction = "written"
+
+// mailPolicyResult is what mail-policy did with one record.
+type mailPolicyResult struct {
+	Name    string               `json:"name"`
+	Content string               `json:"content"`
+	Action  porkbun.EnsureAction `json:"action"`
+	Id      porkbun.RecordID     `json:"id"`
+}
+
+var mailPolicyCmd = &cobra.Command{
+	Use:   "mail-policy DOMAIN",
+	Short: "Publish DMARC, MTA-STS and TLS-RPT records",
+	Long: `Publish DMARC, MTA-STS and TLS-RPT records.
+
+Each record is created, or modified to match, like dns ensure. Only the
+policies given by flags are touched: --dmarc, --mta-sts and --tls-rpt.
+
+The MTA-STS policy is written to --mta-sts-policy-file, to be served from
+https://mta-sts.DOMAIN/.well-known/mta-sts.txt. The id of the _mta-sts record
+is derived from the policy, so it changes whenever the policy does, and
+senders fetch the new one. Senders that see a new id while the old policy is
+still served cache the old policy for its max_age, so change a policy in three
+steps:
+
+  porkbun mail-policy example.com --mta-sts enforce --mta-sts-write-only
+  # upload mta-sts.txt to https://mta-sts.example.com/.well-known/mta-sts.txt
+  porkbun mail-policy example.com --mta-sts enforce
+
+The second run writes the same policy file again, and publishes its id.
+
+DOMAIN is the complete domain, such as 'example.com'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		flags := cmd.Flags()
+
+		ttl, err := flags.GetUint32("ttl")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting ttl var, %w", err))
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
+		var records []porkbun.Record
+
+		if flags.Changed("dmarc") {
+			record, err := dmarcFromFlags(cmd, sub)
+			if err != nil {
+				log.Fatal(err)
+			}
+			records = append(records, record)
+		}
+
+		writeOnly, err := flags.GetBool("mta-sts-write-only")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting mta-sts-write-only var, %w", err))
+		}
+		if writeOnly && !flags.Changed("mta-sts") {
+			log.Fatal("--mta-sts-write-only needs --mta-sts")
+		}
+
+		var results []mailPolicyResult
+
+		if flags.Changed("mta-sts") {
+			record, err := mtaSTSFromFlags(ctx, cmd, client, sub, dom)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if writeOnly {
+				results = append(results, mailPolicyResult{Name: displayName(record.Name), Content: record.Content, Action: mailPolicyWritten})
+			} else {
+				records = append(records, record)
+			}
+		}
+
+		if flags.Changed("tls-rpt") {
+			reports, err := flags.GetStringSlice("tls-rpt")
+			if err != nil {
+				log.Fatal(fmt.Errorf("err getting tls-rpt var, %w", err))
+			}
+
+			record, err := (&mailpolicy.TLSRPT{Reports: reports}).Record(sub)
+			if err != nil {
+				log.Fatal(fmt.Errorf("err building tls-rpt record, %w", err))
+			}
+			records = append(records, record)
+		}
+
+		if len(records) == 0 && !writeOnly {
+			log.Fatal("nothing to publish; pass --dmarc, --mta-sts or --tls-rpt")
+		}
+
+		for _, record := range records {
+			record.TTL = porkbun.TTL(ttl)
+
+			slog.Debug("Sending ensure request", "params", record, "domain", dom)
+
+			res, err := client.EnsureDnsRecord(ctx, dom, &record)
+			if err != nil {
+				log.Fatal(fmt.Errorf("err publishing %q, %w", record.Name, err))
+			}
+
+			results = append(results, mailPolicyResult{Name: displayName(record.Name), Content: record.Content, Action: res.Action, Id: res.Id})
+		}
+
+		printJSON(results)
+	},
+}
+
+// dmarcFromFlags builds the DMARC record from the --dmarc flags.
+func dmarcFromFlags(cmd *cobra.Command, sub string) (porkbun.Record, error) {
+	flags := cmd.Flags()
+	d := &mailpolicy.DMARC{}
+
+	for name, value := range map[string]*string{
+		"dmarc":            (*string)(&d.Policy),
+		"dmarc-subdomains": (*string)(&d.SubdomainPolicy),
+		"dmarc-adkim":      (*string)(&d.DKIMAlignment),
+		"dmarc-aspf":       (*string)(&d.SPFAlignment),
+	} {
+		v, err := flags.GetString(name)
+		if err != nil {
+			return porkbun.Record{}, fmt.Errorf("err getting %s var, %w", name, err)
+		}
+		*value = v
+	}
+
+	var err error
+	if d.AggregateReports, err = flags.GetStringSlice("dmarc-rua"); err != nil {
+		return porkbun.Record{}, fmt.Errorf("err getting dmarc-rua var, %w", err)
+	}
+	if d.FailureReports, err = flags.GetStringSlice("dmarc-ruf"); err != nil {
+		return porkbun.Record{}, fmt.Errorf("err getting dmarc-ruf var, %w", err)
+	}
+
+	if flags.Changed("dmarc-pct") {
+		percent, err := flags.GetInt("dmarc-pct")
+		if err != nil {
+			return porkbun.Record{}, fmt.Errorf("err getting dmarc-pct var, %w", err)
+		}
+		d.Percent = &percent
+	}
+
+	record, err := d.Record(sub)
+	if err != nil {
+		return porkbun.Record{}, fmt.Errorf("err building dmarc record, %w", err)
+	}
+
+	return record, nil
+}
+
+// mtaSTSFromFlags builds the MTA-STS policy from the --mta-sts flags, writes
+// it to the policy file, and returns the record announcing it.
+func mtaSTSFromFlags(ctx context.Context, cmd *cobra.Command, client *porkbun.Client, sub, dom string) (porkbun.Record, error) {
+	flags := cmd.Flags()
+
+	mode, err := flags.GetString("mta-sts")
+	if err != nil {
+		return porkbun.Record{}, fmt.Errorf("err getting mta-sts var, %w", err)
+	}
+
+	mx, err := flags.GetStringSlice("mta-sts-mx")
+	if err != nil {
+		return porkbun.Record{}, fmt.Errorf("err getting mta-sts-mx var, %w", err)
+	}
+
+	maxAge, err := flags.GetDuration("mta-sts-max-age")
+	if err != nil {
+		return porkbun.Record{}, fmt.Errorf("err getting mta-sts-max-age var, %w", err)
+	}
+
+	policyFile, err := flags.GetString("mta-sts-policy-file")
+	if err != nil {
+		return porkbun.Record{}, fmt.Errorf("err getting mta-sts-policy-file var, %w", err)
+	}
+
+	if len(mx) == 0 {
+		res, err := client.ListDnsRecords(ctx, dom, sub, "MX")
+		if err != nil {
+			return porkbun.Record{}, fmt.Errorf("err listing mx records, %w", err)
+		}
+		for _, record := range res.Records {
+			mx = append(mx, record.Content)
+		}
+	}
+
+	policy := &mailpolicy.Policy{Mode: mailpolicy.Mode(mode), MX: mx, MaxAge: maxAge}
+
+	record, err := policy.Record(sub)
+	if err != nil {
+		return porkbun.Record{}, fmt.Errorf("err building mta-sts policy, %w", err)
+	}
+
+	if err := os.WriteFile(policyFile, []byte(policy.String()), 0o644); err != nil {
+		return porkbun.Record{}, fmt.Errorf("err writing mta-sts policy, %w", err)
+	}
+
+	return record, nil
+}
@@ -7,12 +7,20 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/psl"
 	"github.com/spf13/cobra"
 )
 
-var verbose bool
+var (
+	verbose        bool
+	baseUrl        string
+	skipValidation bool
+	names          string
+	suffixList     string
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "porkbun",
@@ -27,7 +35,7 @@ var pingCmd = &cobra.Command{
 	Short: "Ping with authentication",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
-		client, err := porkbun.NewClient()
+		client, err := newClient()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -45,20 +53,129 @@ var pingCmd = &cobra.Command{
 	},
 }
 
+// newClient creates a porkbun client for the base url and validation setting
+// from the command line.
+func newClient() (*porkbun.Client, error) {
+	var options []porkbun.Option
+	if baseUrl != "" {
+		options = append(options, porkbun.WithBaseUrl(baseUrl))
+	}
+	if skipValidation {
+		options = append(options, porkbun.WithoutValidation())
+	}
+
+	return porkbun.NewClient(options...)
+}
+
+// resolveZone splits a name into the name relative to the domain in the
+// account it belongs to, and that domain.
+func resolveZone(ctx context.Context, client *porkbun.Client, name string) (string, string) {
+	zone, err := client.ResolveZone(ctx, name)
+	if err != nil {
+		log.Fatal(fmt.Errorf("err finding zone, %w", err))
+	}
+
+	return zone.Name, zone.Zone
+}
+
+// parseDomain splits a name with the Public Suffix List, for commands that
+// can run without asking the account for its domains.
+func parseDomain(name string) (string, string) {
+	parsed, err := porkbun.ParseDomain(name)
+	if err != nil {
+		log.Fatal(fmt.Errorf("err parsing domain, %w", err))
+	}
+
+	return parsed.Subdomain, parsed.Domain
+}
+
 func initLogger() {
 	if verbose {
 		slog.SetLogLoggerLevel(slog.LevelDebug)
 	}
 }
 
+func initNames() {
+	if names != "ascii" && names != "unicode" {
+		log.Fatalf("unknown --names %q, want ascii or unicode", names)
+	}
+}
+
+func initPublicSuffixList() {
+	if suffixList == "" {
+		return
+	}
+
+	list, err := psl.LoadFile(suffixList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	psl.SetDefault(list)
+}
+
+// printJSON prints v as JSON, or exits if it cannot.
+func printJSON(v any) {
+	resBytes, err := json.Marshal(v)
+	if err != nil {
+		log.Fatal(fmt.Errorf("error marshaling response to JSON, %w", err))
+	}
+	fmt.Println(string(resBytes))
+}
+
+// displayName returns a domain name in the form chosen with --names.
+func displayName(name string) string {
+	if names == "unicode" {
+		return porkbun.ToUnicode(name)
+	}
+
+	return name
+}
+
+// displayRecords returns copies of records with their names, and host names
+// in their content, in the form chosen with --names.
+func displayRecords(records []porkbun.Record) []porkbun.Record {
+	displayed := make([]porkbun.Record, len(records))
+	for i, record := range records {
+		record.Name = displayName(record.Name)
+		switch strings.ToUpper(record.Type) {
+		case "ALIAS", "CNAME", "MX", "NS":
+			record.Content = displayName(record.Content)
+		}
+		displayed[i] = record
+	}
+
+	return displayed
+}
+
 func init() {
-	cobra.OnInitialize(initLogger)
+	cobra.OnInitialize(initLogger, initNames, initPublicSuffixList)
 
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Output verbose logs")
+	rootCmd.PersistentFlags().StringVar(&baseUrl, "base-url", "", "url of the porkbun api, such as a local mock-server")
+	rootCmd.PersistentFlags().BoolVar(&skipValidation, "skip-validation", false, "send records to porkbun without checking them first")
+	rootCmd.PersistentFlags().StringVar(&suffixList, "public-suffix-list", "", "public_suffix_list.dat to split domains with, instead of the built in copy")
+	rootCmd.PersistentFlags().StringVar(&names, "names", "ascii", "show internationalized domain names as ascii (punycode) or unicode")
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(dkimCmd)
 	rootCmd.AddCommand(dnsCmd)
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(mailPolicyCmd)
+	rootCmd.AddCommand(mockServerCmd)
 	rootCmd.AddCommand(pingCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(spfCmd)
 
 	initDnsCmd()
+	initZoneFileCmd()
+	initLockCmd()
+	initTlsaCmd()
+	initSpfCmd()
+	initDkimCmd()
+	initMailPolicyCmd()
+	initAuditCmd()
+	initMockServerCmd()
+	initPlanCmd()
 }
 
 func main() {
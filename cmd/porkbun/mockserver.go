@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+func initMockServerCmd() {
+	mockServerFlags := mockServerCmd.Flags()
+	mockServerFlags.String("listen", "localhost:8080", "address to serve the api on")
+	mockServerFlags.StringP("fixture", "f", "", "YAML or JSON file of domains and records to start with")
+	mockServerFlags.String("save", "", "YAML or JSON file to write the state to on shutdown")
+	mockServerFlags.String("api-key", porkbuntest.DefaultApiKey, "api key the server accepts")
+	mockServerFlags.String("secret-key", porkbuntest.DefaultSecretKey, "secret key the server accepts")
+}
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Run a fake porkbun api locally",
+	Long: `Run a fake porkbun api locally.
+
+The server keeps domains and records in memory, and implements the DNS, ping,
+domain and URL forwarding endpoints. Point the CLI at it with --base-url, and
+use the keys from --api-key and --secret-key:
+
+  export PORKBUN_API_KEY=apikey PORKBUN_SECRET_KEY=secretkey
+  porkbun mock-server --fixture zones.yaml &
+  porkbun --base-url http://localhost:8080 dns list example.com
+
+A fixture lists domains with their records:
+
+  domains:
+    - domain: example.com
+      records:
+        - name: www
+          type: A
+          content: 10.0.0.1`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting listen var, %w", err))
+		}
+
+		fixture, err := cmd.Flags().GetString("fixture")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting fixture var, %w", err))
+		}
+
+		save, err := cmd.Flags().GetString("save")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting save var, %w", err))
+		}
+
+		apiKey, err := cmd.Flags().GetString("api-key")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting api-key var, %w", err))
+		}
+
+		secretKey, err := cmd.Flags().GetString("secret-key")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting secret-key var, %w", err))
+		}
+
+		backend := porkbuntest.NewBackend(porkbuntest.WithCredentials(apiKey, secretKey))
+
+		if fixture != "" {
+			state, err := readState(fixture)
+			if err != nil {
+				log.Fatal(fmt.Errorf("err reading fixture, %w", err))
+			}
+
+			if err := backend.Restore(state); err != nil {
+				log.Fatal(fmt.Errorf("err loading fixture, %w", err))
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := &http.Server{Addr: listen, Handler: backend}
+		go func() {
+			<-ctx.Done()
+			server.Shutdown(context.Background())
+		}()
+
+		slog.Info("Serving mock porkbun api", "address", listen, "domains", backend.Domains())
+
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(fmt.Errorf("err serving mock api, %w", err))
+		}
+
+		if save != "" {
+			slog.Debug("Saving state", "file", save)
+
+			if err := writeState(save, backend.State()); err != nil {
+				log.Fatal(fmt.Errorf("err saving state, %w", err))
+			}
+		}
+	},
+}
+
+// readState loads mock-server state from a JSON file, or a YAML file when the
+// extension is .yaml or .yml.
+func readState(path string) (porkbuntest.State, error) {
+	var state porkbuntest.State
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+
+	if isYaml(path) {
+		err = yaml.Unmarshal(data, &state)
+	} else {
+		err = json.Unmarshal(data, &state)
+	}
+
+	return state, err
+}
+
+func writeState(path string, state porkbuntest.State) error {
+	var data []byte
+	var err error
+
+	if isYaml(path) {
+		data, err = yaml.Marshal(state)
+	} else {
+		data, err = json.MarshalIndent(state, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func isYaml(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/diff"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// planChangesExitCode is the exit code of plan when there are changes to make.
+const planChangesExitCode = 2
+
+func initPlanCmd() {
+	planCmd.Flags().StringP("file", "f", "", "YAML or JSON file of the records each domain should have")
+	planCmd.MarkFlagRequired("file")
+
+	applyCmd.Flags().StringP("file", "f", "", "YAML or JSON file of the records each domain should have")
+	applyCmd.MarkFlagRequired("file")
+}
+
+const desiredStateHelp = `The file lists domains and every record they should have, in YAML, or in
+JSON when the extension is .json:
+
+  domains:
+    - domain: example.com
+      records:
+        - {name: "", type: A, content: 192.0.2.1}
+        - {name: www, type: CNAME, content: example.com, ttl: 3600}
+        - {name: "", type: MX, content: mail.example.com, prio: 10}
+
+Names are relative to the domain, with "" or "@" for the domain itself, or in
+full, as in www.example.com, and the TTL defaults to 600. Records a domain has that are not listed are deleted,
+except for the NS records of the domain itself, which are only managed when
+some are listed. Domains that are not listed are left alone.`
+
+var planCmd = &cobra.Command{
+	Use:   "plan -f FILE",
+	Short: "Show the changes that make domains match a file",
+	Long: `Show the changes that make domains match a file.
+
+The records of each domain are compared with the file, and the records to
+create, update and delete are shown as a diff. Nothing is changed; see apply.
+
+The exit code is 0 when there is nothing to change, and 2 when there are
+changes to make.
+
+` + desiredStateHelp,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		_, changes := planFromFile(ctx, cmd)
+
+		if len(changes) == 0 {
+			fmt.Println("No changes.")
+			return
+		}
+
+		printChanges(os.Stdout, changes)
+		printPlanSummary(changes)
+
+		// So CI can tell there are changes without reading the diff.
+		os.Exit(planChangesExitCode)
+	},
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f FILE",
+	Short: "Make domains match a file",
+	Long: `Make domains match a file.
+
+The changes plan shows are made, in order: deletes, then updates, then
+creates, so a record can take the place of others at its name. If a change
+fails, the changes made before it are undone.
+
+` + desiredStateHelp,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		client, changes := planFromFile(ctx, cmd)
+
+		if len(changes) == 0 {
+			fmt.Println("No changes.")
+			return
+		}
+
+		printChanges(os.Stdout, changes)
+		printPlanSummary(changes)
+
+		slog.Debug("Applying change set", "changes", len(changes))
+
+		if _, err := newChangeSet(client, changes).Apply(ctx); err != nil {
+			log.Fatal(fmt.Errorf("err applying changes, %w", err))
+		}
+
+		fmt.Printf("Applied %d changes.\n", len(changes))
+	},
+}
+
+// planFromFile reads the file given to plan or apply, and returns the changes
+// that make the domains in it match.
+func planFromFile(ctx context.Context, cmd *cobra.Command) (*porkbun.Client, []recordChange) {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		log.Fatal(fmt.Errorf("err getting file var, %w", err))
+	}
+
+	state, err := readDesiredState(file)
+	if err != nil {
+		log.Fatal(fmt.Errorf("err reading %s, %w", file, err))
+	}
+
+	if err := state.Validate(); err != nil {
+		log.Fatal(fmt.Errorf("err validating %s, %w", file, err))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+	}
+
+	changes, err := planChanges(ctx, client, state)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return client, changes
+}
+
+// planChanges returns the changes that make the domains in state match it.
+func planChanges(ctx context.Context, client *porkbun.Client, state porkbun.DesiredState) ([]recordChange, error) {
+	var changes []recordChange
+	for _, desired := range state.Domains {
+		domain, err := porkbun.ToASCII(strings.TrimSuffix(desired.Domain, "."))
+		if err != nil {
+			return nil, err
+		}
+		domain = strings.ToLower(domain)
+
+		slog.Debug("Sending list request", "domain", domain)
+
+		live, err := client.ListDnsRecords(ctx, domain, "", "")
+		if err != nil {
+			return nil, fmt.Errorf("err listing dns records of %s, %w", domain, err)
+		}
+
+		records := make([]porkbun.Record, len(desired.Records))
+		manageApexNS := false
+		for i, record := range desired.Records {
+			// The records are created and modified with this name, which
+			// Porkbun puts in front of the domain.
+			record.Name = relativeTo(record.Name, domain)
+			records[i] = record
+
+			if record.Name == "" && strings.EqualFold(record.Type, "NS") {
+				manageApexNS = true
+			}
+		}
+
+		for _, change := range domainChanges(domain, live.Records, records) {
+			isApexNS := change.Kind == diff.Delete &&
+				strings.EqualFold(change.Before.Type, "NS") &&
+				strings.EqualFold(change.Before.Name, domain)
+			if isApexNS && !manageApexNS {
+				continue
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+// relativeTo returns a record name from the file relative to domain. Names
+// may be relative already, or in full with or without a trailing dot, as
+// diff.WithDomain takes them.
+func relativeTo(name, domain string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "@" || name == domain {
+		return ""
+	}
+
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+// readDesiredState loads the file given to plan or apply, from JSON when the
+// extension is .json, and from YAML otherwise. Unknown fields are an error,
+// so a misspelt one is not quietly ignored.
+func readDesiredState(path string) (porkbun.DesiredState, error) {
+	var state porkbun.DesiredState
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&state)
+	} else {
+		err = yaml.UnmarshalStrict(data, &state)
+	}
+
+	return state, err
+}
+
+func printPlanSummary(changes []recordChange) {
+	creates, updates, deletes := countChanges(changes)
+	fmt.Printf("\n%d to create, %d to update, %d to delete\n", creates, updates, deletes)
+}
+
+// recordChange is a change to the records of a domain.
+type recordChange struct {
+	Domain string
+	diff.Change
+}
+
+// domainChanges returns the changes that make the live records of a domain
+// match the desired ones.
+func domainChanges(domain string, live, desired []porkbun.Record, options ...diff.Option) []recordChange {
+	options = append([]diff.Option{diff.WithDomain(domain)}, options...)
+
+	var changes []recordChange
+	for _, change := range diff.Records(live, desired, options...) {
+		changes = append(changes, recordChange{Domain: domain, Change: change})
+	}
+
+	return changes
+}
+
+func countChanges(changes []recordChange) (creates, updates, deletes int) {
+	plain := make([]diff.Change, len(changes))
+	for i, change := range changes {
+		plain[i] = change.Change
+	}
+
+	return diff.Count(plain)
+}
+
+// printChanges shows changes as a diff: a line starting with + for each
+// record to create, - for each to delete, and both for each to update.
+func printChanges(w io.Writer, changes []recordChange) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+
+	line := func(sign string, record *porkbun.Record, name string) {
+		content := record.Content
+		if record.Priority != 0 {
+			content = fmt.Sprintf("%d %s", record.Priority, content)
+		}
+		fmt.Fprintf(tw, "%s %s\t%d\t%s\t%s\n", sign, displayName(name), max(record.TTL, 600), strings.ToUpper(record.Type), content)
+	}
+
+	for _, change := range changes {
+		if change.Before != nil {
+			line("-", change.Before, change.Before.Name)
+		}
+		if change.After != nil {
+			line("+", change.After, joinName(change.After.Name, change.Domain))
+		}
+	}
+
+	tw.Flush()
+}
+
+// newChangeSet builds a change set making changes: deletes first, then
+// updates, then creates, so a record can take the place of others at its
+// name.
+func newChangeSet(client *porkbun.Client, changes []recordChange) *porkbun.ChangeSet {
+	cs := client.NewChangeSet()
+
+	for _, change := range changes {
+		if change.Kind == diff.Delete {
+			cs.Delete(change.Domain, porkbun.Record{Id: change.Before.Id, Name: change.Before.Name, Type: change.Before.Type})
+		}
+	}
+
+	for _, change := range changes {
+		if change.Kind == diff.Update {
+			modified := *change.After
+			modified.Id = change.Before.Id
+			cs.Modify(change.Domain, modified)
+		}
+	}
+
+	for _, change := range changes {
+		if change.Kind == diff.Create {
+			cs.Create(change.Domain, *change.After)
+		}
+	}
+
+	return cs
+}
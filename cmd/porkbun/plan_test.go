@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestPlanApply(t *testing.T) {
+	ctx := context.TODO()
+
+	server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+	defer server.Close()
+	server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "192.0.2.1"})
+	server.Backend.AddRecord("example.com", porkbun.Record{Name: "old", Type: "A", Content: "192.0.2.9"})
+
+	client, err := porkbun.NewClient(
+		porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+		porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+		porkbun.WithBaseUrl(server.URL),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := porkbun.DesiredState{Domains: []porkbun.DesiredDomain{{
+		Domain: "example.com",
+		Records: []porkbun.Record{
+			{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 3600},
+			{Name: "api.example.com.", Type: "A", Content: "192.0.2.2"},
+			{Name: "@", Type: "MX", Content: "mail.example.com", Priority: 10},
+			{Name: "Mail", Type: "A", Content: "192.0.2.3"},
+		},
+	}}}
+
+	changes, err := planChanges(ctx, client, state)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if creates, updates, deletes := countChanges(changes); creates != 3 || updates != 1 || deletes != 1 {
+		t.Errorf("got %d, %d, %d, want 3, 1, 1", creates, updates, deletes)
+	}
+
+	if _, err := newChangeSet(client, changes).Apply(ctx); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	for _, record := range server.Backend.Records("example.com") {
+		if strings.Count(record.Name, "example.com") != 1 {
+			t.Errorf("got %q, want a name in example.com", record.Name)
+		}
+	}
+
+	changes, err = planChanges(ctx, client, state)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if len(changes) != 0 {
+		t.Errorf("got %d changes after applying, want 0", len(changes))
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/spf"
+	"github.com/spf13/cobra"
+)
+
+func initSpfCmd() {
+	spfCmd.AddCommand(spfCheckCmd)
+	spfCmd.AddCommand(spfFlattenCmd)
+
+	flags := spfFlattenCmd.Flags()
+	flags.String("source", "", "spf record to flatten instead of the published one, to refresh a record flattened before")
+	flags.Int("max-length", spf.DefaultMaxLength, "longest record to publish before chaining to another")
+	flags.Uint32("ttl", 600, "time to live for the records, in seconds")
+	flags.Bool("publish", false, "publish the flattened records")
+}
+
+var spfCmd = &cobra.Command{
+	Use:   "spf",
+	Short: "Check and flatten SPF records",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// spfCheck is the output of spf check.
+type spfCheck struct {
+	Content string `json:"content"`
+	Lookups int    `json:"lookups"`
+	Limit   int    `json:"limit"`
+}
+
+var spfCheckCmd = &cobra.Command{
+	Use:   "check DOMAIN",
+	Short: "Count the DNS lookups of an SPF record",
+	Long: `Count the DNS lookups of an SPF record.
+
+The record is read from Porkbun, and its includes are followed through DNS.
+The command exits with 1 if it takes more than 10 lookups.
+
+DOMAIN is the complete domain, such as 'example.com'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
+		current, err := findSpf(ctx, client, dom, sub)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		record, err := spf.Parse(current.Content)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err parsing spf record, %w", err))
+		}
+
+		lookups, err := spf.CountLookups(ctx, spf.DefaultResolver, args[0], record)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err counting lookups, %w", err))
+		}
+
+		printJSON(spfCheck{Content: record.String(), Lookups: lookups, Limit: spf.MaxLookups})
+		if lookups > spf.MaxLookups {
+			os.Exit(1)
+		}
+	},
+}
+
+// spfPublished is what spf flatten did with one record of the chain.
+type spfPublished struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	Action  string `json:"action"`
+}
+
+var spfFlattenCmd = &cobra.Command{
+	Use:   "flatten DOMAIN",
+	Short: "Replace the includes of an SPF record with addresses",
+	Long: `Replace the includes of an SPF record with addresses.
+
+The includes, a and mx mechanisms are resolved to the ip4 and ip6 networks
+they stand for, so the record takes fewer DNS lookups. If the result is longer
+than --max-length, it is split over records at _spf1, _spf2... under the
+name, each including the next. By default, the records are printed as JSON.
+
+With --publish, the chained records are created or modified from the end of
+the chain back, then the SPF record at DOMAIN is modified, so every record is
+in place before anything includes it. Chained records left over from a longer
+chain are deleted last.
+
+A flattened record has no includes left, so keep the original and pass it
+with --source to flatten it again when the addresses change.
+
+DOMAIN is the complete domain, such as 'example.com'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		flags := cmd.Flags()
+
+		source, err := flags.GetString("source")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting source var, %w", err))
+		}
+
+		maxLength, err := flags.GetInt("max-length")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting max-length var, %w", err))
+		}
+
+		ttl, err := flags.GetUint32("ttl")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting ttl var, %w", err))
+		}
+
+		publish, err := flags.GetBool("publish")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting publish var, %w", err))
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		// Only a record read from or published to the account needs the zone
+		// from the account.
+		var sub, dom string
+		var current *porkbun.Record
+		if source == "" || publish {
+			sub, dom = resolveZone(ctx, client, args[0])
+			if current, err = findSpf(ctx, client, dom, sub); err != nil {
+				log.Fatal(err)
+			}
+		} else {
+			sub, dom = parseDomain(args[0])
+		}
+		if source == "" {
+			source = current.Content
+		}
+
+		record, err := spf.Parse(source)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err parsing spf record, %w", err))
+		}
+
+		flat, err := spf.Flatten(ctx, spf.DefaultResolver, args[0], record)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err flattening spf record, %w", err))
+		}
+
+		parts, err := spf.Split(flat, sub, dom, maxLength)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err splitting spf record, %w", err))
+		}
+
+		if !publish {
+			printJSON(parts)
+			return
+		}
+
+		// Publish from the tail of the chain back to the head, so no record
+		// includes one that does not hold its new content yet.
+		published := make([]spfPublished, len(parts))
+		for i := len(parts) - 1; i >= 0; i-- {
+			part := parts[i]
+			existing := current
+			if i > 0 {
+				existing, err = findSpf(ctx, client, dom, part.Name)
+				if errors.Is(err, spf.ErrNoRecord) {
+					existing, err = nil, nil
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			result := spfPublished{Name: displayName(part.Name), Content: part.Content}
+			desired := &porkbun.Record{Name: part.Name, Type: "TXT", Content: part.Content, TTL: porkbun.TTL(ttl)}
+
+			switch {
+			case existing == nil:
+				slog.Debug("Sending create request", "params", desired, "domain", dom)
+				_, err = client.CreateDnsRecord(ctx, dom, desired)
+				result.Action = string(porkbun.EnsureCreated)
+			case existing.TextValue() != porkbun.JoinText(part.Content):
+				desired.Id = existing.Id
+				slog.Debug("Sending modify request", "params", desired, "domain", dom)
+				_, err = client.ModifyDnsRecord(ctx, dom, desired)
+				result.Action = string(porkbun.EnsureModified)
+			default:
+				result.Action = string(porkbun.EnsureUnchanged)
+			}
+			if err != nil {
+				log.Fatal(fmt.Errorf("err publishing %q, %w", part.Name, err))
+			}
+
+			published[i] = result
+		}
+
+		// Records past the end of the chain are no longer included by anything.
+		for n := len(parts); ; n++ {
+			name := spf.ChainName(sub, n)
+			stale, err := findSpf(ctx, client, dom, name)
+			if errors.Is(err, spf.ErrNoRecord) {
+				break
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			slog.Debug("Sending delete request", "id", stale.Id, "domain", dom)
+			if _, err := client.DeleteDnsRecordById(ctx, dom, stale.Id); err != nil {
+				log.Fatal(fmt.Errorf("err deleting %q, %w", name, err))
+			}
+
+			published = append(published, spfPublished{Name: displayName(name), Content: stale.Content, Action: "deleted"})
+		}
+
+		printJSON(published)
+	},
+}
+
+// findSpf returns the SPF record at a subdomain.
+func findSpf(ctx context.Context, client *porkbun.Client, domain, subdomain string) (*porkbun.Record, error) {
+	res, err := client.ListDnsRecords(ctx, domain, subdomain, "TXT")
+	if err != nil {
+		return nil, fmt.Errorf("err listing txt records, %w", err)
+	}
+
+	return spf.Find(res.Records)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/spf13/cobra"
+)
+
+func initTlsaCmd() {
+	dnsCmd.AddCommand(dnsTlsaCmd)
+
+	flags := dnsTlsaCmd.Flags()
+	flags.Uint16("port", 25, "port of the service")
+	flags.String("protocol", "tcp", "protocol of the service")
+	flags.Uint8("usage", uint8(porkbun.TLSAUsageDANEEE), "certificate usage: 0 PKIX-TA, 1 PKIX-EE, 2 DANE-TA, 3 DANE-EE")
+	flags.Uint8("selector", uint8(porkbun.TLSASelectorSPKI), "selector: 0 whole certificate, 1 public key")
+	flags.Uint8("matching-type", uint8(porkbun.TLSAMatchingSHA256), "matching type: 0 full, 1 SHA-256, 2 SHA-512")
+	flags.Uint32("ttl", 600, "time to live for the record, in seconds")
+	flags.Bool("publish", false, "create the record, unless it already exists")
+	flags.Bool("check", false, "check the published records against the certificate instead")
+}
+
+// tlsaCheck is the result of checking one published TLSA record.
+type tlsaCheck struct {
+	Id      porkbun.RecordID `json:"id"`
+	Content string           `json:"content"`
+	Matches bool             `json:"matches"`
+}
+
+var dnsTlsaCmd = &cobra.Command{
+	Use:   "tlsa HOST CERT_FILE",
+	Short: "Generate, publish or check TLSA records for a certificate",
+	Long: `Generate, publish or check TLSA records for a certificate.
+
+The record is named for the port and protocol of the service on HOST, such as
+'_25._tcp.mx.example.com'. By default, the record is printed as JSON.
+
+With --publish, the record is created unless one with the same content is
+already there. Existing records are left alone, so the old and new
+certificates can both be published during a rotation.
+
+With --check, every TLSA record at the name is compared with the certificate,
+and the ones that no longer match are reported. The command exits with 1 if
+there are any, or if there are no TLSA records at the name at all.
+
+HOST is the complete host name, such as 'mx.example.com'.
+CERT_FILE is a PEM certificate, chain or public key.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		flags := cmd.Flags()
+
+		port, err := flags.GetUint16("port")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting port var, %w", err))
+		}
+
+		protocol, err := flags.GetString("protocol")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting protocol var, %w", err))
+		}
+
+		usage, err := flags.GetUint8("usage")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting usage var, %w", err))
+		}
+
+		selector, err := flags.GetUint8("selector")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting selector var, %w", err))
+		}
+
+		matching, err := flags.GetUint8("matching-type")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting matching-type var, %w", err))
+		}
+
+		ttl, err := flags.GetUint32("ttl")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting ttl var, %w", err))
+		}
+
+		publish, err := flags.GetBool("publish")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting publish var, %w", err))
+		}
+
+		check, err := flags.GetBool("check")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting check var, %w", err))
+		}
+
+		certPEM, err := os.ReadFile(args[1])
+		if err != nil {
+			log.Fatal(fmt.Errorf("err reading certificate, %w", err))
+		}
+
+		data, err := porkbun.NewTLSAData(
+			certPEM,
+			porkbun.TLSAUsage(usage),
+			porkbun.TLSASelector(selector),
+			porkbun.TLSAMatchingType(matching),
+		)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err building tlsa record, %w", err))
+		}
+
+		if !publish && !check {
+			sub, _ := parseDomain(args[0])
+			record := data.ToRecord(porkbun.TLSAName(port, protocol, sub))
+			record.TTL = porkbun.TTL(ttl)
+			record.Name = displayName(record.Name)
+			printJSON(record)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
+		name := porkbun.TLSAName(port, protocol, sub)
+		record := data.ToRecord(name)
+		record.TTL = porkbun.TTL(ttl)
+
+		existing, err := client.ListDnsRecords(ctx, dom, name, "TLSA")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err listing tlsa records, %w", err))
+		}
+
+		if check {
+			if len(existing.Records) == 0 {
+				printJSON([]tlsaCheck{})
+				log.Fatalf("err checking tlsa records, none found at %q", displayName(porkbun.TLSAName(port, protocol, args[0])))
+			}
+
+			var stale bool
+			checks := []tlsaCheck{}
+			for _, r := range existing.Records {
+				result := tlsaCheck{Id: r.Id, Content: r.Content}
+
+				parsed, err := porkbun.ParseRecord(r)
+				if err == nil {
+					result.Matches, err = parsed.(*porkbun.TLSAData).MatchesPEM(certPEM)
+				}
+				if err != nil {
+					slog.Debug("Could not check record", "id", r.Id, "err", err)
+				}
+
+				stale = stale || !result.Matches
+				checks = append(checks, result)
+			}
+
+			printJSON(checks)
+			if stale {
+				os.Exit(1)
+			}
+			return
+		}
+
+		for _, r := range existing.Records {
+			if r.Content == record.Content {
+				printJSON(porkbun.EnsureResult{Action: porkbun.EnsureUnchanged, Id: r.Id})
+				return
+			}
+		}
+
+		slog.Debug("Sending create request", "params", record, "domain", dom)
+
+		res, err := client.CreateDnsRecord(ctx, dom, &record)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating dns record, %w", err))
+		}
+
+		printJSON(porkbun.EnsureResult{Action: porkbun.EnsureCreated, Id: res.Id})
+	},
+}
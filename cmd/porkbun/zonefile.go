@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/diff"
+	"github.com/spf13/cobra"
+)
+
+func initZoneFileCmd() {
+	dnsCmd.AddCommand(dnsExportCmd)
+	dnsCmd.AddCommand(dnsImportCmd)
+
+	exportFlags := dnsExportCmd.Flags()
+	exportFlags.String("format", "bind", "output format: bind or json")
+	exportFlags.String("alias-type", "", "write ALIAS records as this pseudo-type, such as ALIAS or ANAME, instead of as comments")
+
+	importFlags := dnsImportCmd.Flags()
+	importFlags.String("alias-type", "", "read records of this pseudo-type, such as ANAME, as ALIAS records")
+	importFlags.Bool("apex-ns", false, "import the NS records of the domain itself, instead of skipping them")
+	importFlags.BoolP("yes", "y", false, "apply the changes without asking")
+}
+
+var dnsExportCmd = &cobra.Command{
+	Use:   "export DOMAIN",
+	Short: "Export the records of a domain as a zone file",
+	Long: `Export the records of a domain as a zone file.
+
+With --format bind, the records are written as an RFC 1035 master file, as
+read by BIND and most other DNS servers, with names relative to the domain.
+Porkbun has no SOA record to export, so the server loading the file has to
+add its own. ALIAS records are only understood by some servers, so they are
+commented out unless --alias-type is given.
+
+With --format json, the records are written as JSON, as by 'dns list'.
+
+Zone files always hold punycode names, whatever --names says.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		flags := cmd.Flags()
+
+		format, err := flags.GetString("format")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting format var, %w", err))
+		}
+		if format != "bind" && format != "json" {
+			log.Fatalf("unknown format %q, want bind or json", format)
+		}
+
+		aliasType, err := flags.GetString("alias-type")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting alias-type var, %w", err))
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		_, dom := resolveZone(ctx, client, args[0])
+
+		slog.Debug("Sending list request", "domain", dom)
+
+		res, err := client.ListDnsRecords(ctx, dom, "", "")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err listing dns records, %w", err))
+		}
+
+		if format == "json" {
+			printJSON(displayRecords(res.Records))
+			return
+		}
+
+		var options []porkbun.ZoneFileOption
+		if aliasType != "" {
+			options = append(options, porkbun.WithAliasType(aliasType))
+		}
+
+		if err := porkbun.WriteZoneFile(os.Stdout, dom, res.Records, options...); err != nil {
+			log.Fatal(fmt.Errorf("err writing zone file, %w", err))
+		}
+	},
+}
+
+var dnsImportCmd = &cobra.Command{
+	Use:   "import DOMAIN FILE",
+	Short: "Import the records of a zone file into a domain",
+	Long: `Import the records of a zone file into a domain.
+
+FILE is an RFC 1035 master file, as exported by BIND and most other DNS
+servers, holding the records of DOMAIN. $ORIGIN, $TTL and $INCLUDE are
+followed, and names are relative to DOMAIN until an $ORIGIN says otherwise.
+The SOA record is skipped, and so are the NS records of DOMAIN itself unless
+--apex-ns is given. TTLs below Porkbun's minimum of 600 are raised to it.
+
+The records are compared with the ones DOMAIN has now, and the changes are
+shown as a diff: records to create, and records to update where DOMAIN has one
+with the same content but another TTL or priority. Records only DOMAIN has are
+left alone, so a record in the file is added next to the others at its name,
+such as a TXT record next to a site verification. Once confirmed, the changes are applied in order, and undone if one of
+them fails.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		flags := cmd.Flags()
+
+		aliasType, err := flags.GetString("alias-type")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting alias-type var, %w", err))
+		}
+
+		apexNS, err := flags.GetBool("apex-ns")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting apex-ns var, %w", err))
+		}
+
+		yes, err := flags.GetBool("yes")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err getting yes var, %w", err))
+		}
+
+		var options []porkbun.ZoneFileOption
+		if aliasType != "" {
+			options = append(options, porkbun.WithAliasType(aliasType))
+		}
+		if apexNS {
+			options = append(options, porkbun.WithApexNS())
+		}
+
+		client, err := newClient()
+		if err != nil {
+			log.Fatal(fmt.Errorf("err creating porkbun client, %w", err))
+		}
+
+		sub, dom := resolveZone(ctx, client, args[0])
+
+		records, err := porkbun.LoadZoneFile(args[1], args[0], options...)
+		if err != nil {
+			log.Fatal(fmt.Errorf("err reading zone file, %w", err))
+		}
+
+		// The file may be for a subdomain of the zone.
+		for i := range records {
+			records[i].Name = joinName(records[i].Name, sub)
+			records[i].TTL = max(records[i].TTL, 600)
+		}
+
+		slog.Debug("Sending list request", "domain", dom)
+
+		live, err := client.ListDnsRecords(ctx, dom, "", "")
+		if err != nil {
+			log.Fatal(fmt.Errorf("err listing dns records, %w", err))
+		}
+
+		// Records only the domain has are left alone, not deleted.
+		var changes []recordChange
+		var untouched int
+		for _, change := range domainChanges(dom, live.Records, records, diff.WithoutPairing()) {
+			if change.Kind == diff.Delete {
+				untouched++
+				continue
+			}
+			changes = append(changes, change)
+		}
+
+		if len(changes) == 0 {
+			fmt.Printf("No changes, %d left alone\n", untouched)
+			return
+		}
+
+		printChanges(os.Stdout, changes)
+
+		creates, updates, _ := countChanges(changes)
+		fmt.Printf("\n%d to create, %d to update, %d left alone\n", creates, updates, untouched)
+
+		if !yes && !confirm(fmt.Sprintf("Apply %d changes to %s?", len(changes), dom)) {
+			fmt.Println("Nothing was changed.")
+			return
+		}
+
+		cs := newChangeSet(client, changes)
+
+		slog.Debug("Applying change set", "domain", dom, "changes", len(changes))
+
+		if _, err := cs.Apply(ctx); err != nil {
+			log.Fatal(fmt.Errorf("err importing records, %w", err))
+		}
+
+		fmt.Printf("Applied %d changes to %s.\n", len(changes), dom)
+	},
+}
+
+// confirm asks a yes or no question on stderr, and reads the answer from
+// stdin. Anything but yes is no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// joinName puts name in front of parent, either of which may be empty, as a
+// subdomain is joined onto a domain.
+func joinName(name, parent string) string {
+	switch {
+	case name == "":
+		return parent
+	case parent == "":
+		return name
+	}
+
+	return name + "." + parent
+}
@@ -0,0 +1,65 @@
+package porkbun
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DesiredState is the records a set of domains should have, as read from a
+// YAML or JSON file by porkbun plan and apply:
+//
+//	domains:
+//	  - domain: example.com
+//	    records:
+//	      - {name: "", type: A, content: 192.0.2.1}
+//	      - {name: www, type: CNAME, content: example.com, ttl: 3600}
+//	      - {name: "", type: MX, content: mail.example.com, prio: 10}
+type DesiredState struct {
+	Domains []DesiredDomain `json:"domains"`
+}
+
+// DesiredDomain is a domain and every record it should have. Records it has
+// that are not listed are deleted, except for the NS records of the domain
+// itself, which are only managed when some are listed.
+type DesiredDomain struct {
+	Domain string `json:"domain"`
+
+	// Names are relative to the domain, with "" or "@" for the domain itself,
+	// or in full, with or without a trailing dot. A TTL of 0 is the default
+	// of 600 seconds.
+	Records []Record `json:"records"`
+}
+
+// Validate checks that the domain names are valid and listed once, and that
+// every record passes Record.Validate. It returns every problem found, joined.
+func (s DesiredState) Validate() error {
+	var errs []error
+	seen := map[string]bool{}
+
+	for _, domain := range s.Domains {
+		name, err := ToASCII(strings.TrimSuffix(domain.Domain, "."))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		name = strings.ToLower(name)
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("domain %q is listed more than once", domain.Domain))
+		}
+		seen[name] = true
+
+		for i, record := range domain.Records {
+			if record.Name == "@" {
+				record.Name = ""
+			}
+
+			if err := record.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("domain %q record %d, %w", domain.Domain, i, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
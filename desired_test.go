@@ -0,0 +1,45 @@
+package porkbun_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+func TestDesiredStateValidate(t *testing.T) {
+	valid := porkbun.DesiredState{Domains: []porkbun.DesiredDomain{
+		{Domain: "example.com", Records: []porkbun.Record{
+			{Name: "@", Type: "A", Content: "192.0.2.1"},
+			{Name: "www", Type: "CNAME", Content: "example.com", TTL: 3600},
+		}},
+		{Domain: "bücher.de"},
+	}}
+
+	if err := valid.Validate(); err != nil {
+		t.Errorf("got %s, want nil", err)
+	}
+
+	invalid := porkbun.DesiredState{Domains: []porkbun.DesiredDomain{
+		{Domain: "example.com", Records: []porkbun.Record{
+			{Name: "www", Type: "A", Content: "not an address"},
+			{Name: "", Type: "MX", Content: "mail.example.com", TTL: 60},
+		}},
+		{Domain: "Example.com."},
+		{Domain: "-bücher.de"},
+	}}
+
+	err := invalid.Validate()
+
+	var validation *porkbun.ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("got %v, want a *ValidationError", err)
+	}
+
+	for _, want := range []string{"record 0", "record 1", "listed more than once", "-bücher.de"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got %q, want it to mention %q", err, want)
+		}
+	}
+}
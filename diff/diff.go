@@ -0,0 +1,268 @@
+// Package diff compares two sets of records, such as the live records of a
+// domain and the records it should have, and returns the changes that turn
+// one into the other.
+//
+// Records are grouped into record sets by name and type, and matched by
+// content within a set, so a name may hold several records of a type, like a
+// few A records. Differences that Porkbun does not keep, such as a trailing
+// dot or the case of a name, are ignored as set by a Normalization.
+//
+//	changes := diff.Records(live.Records, desired, diff.WithDomain("example.com"))
+package diff
+
+import (
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+type Kind string
+
+const (
+	Create Kind = "create"
+	Update Kind = "update"
+	Delete Kind = "delete"
+)
+
+// Change is a record to create, update or delete. The records are the ones
+// given to Records, not normalized.
+type Change struct {
+	Kind Kind `json:"kind"`
+
+	// The record as it is, or nil for a Create.
+	Before *porkbun.Record `json:"before,omitempty"`
+
+	// The record as it should be, or nil for a Delete.
+	After *porkbun.Record `json:"after,omitempty"`
+}
+
+// Normalization says which differences between records do not count.
+type Normalization struct {
+	// Ignore a trailing dot on names and host names, so "www.example.com."
+	// is the same as "www.example.com".
+	IgnoreTrailingDot bool
+
+	// Compare names and host names without regard to case. Types are always
+	// compared that way.
+	IgnoreCase bool
+
+	// The TTL of records with a TTL of zero. Lower TTLs are raised to it.
+	// Zero compares TTLs as they are.
+	DefaultTTL porkbun.TTL
+
+	// Ignore the priority of records other than MX and SRV, which have no use
+	// for one.
+	IgnoreUnusedPriority bool
+}
+
+// Porkbun is the Normalization that matches how Porkbun keeps records, and
+// the default.
+var Porkbun = Normalization{
+	IgnoreTrailingDot:    true,
+	IgnoreCase:           true,
+	DefaultTTL:           600,
+	IgnoreUnusedPriority: true,
+}
+
+// Option changes how Records compares records.
+type Option func(*differ)
+
+type differ struct {
+	domain        string
+	normalization Normalization
+	unpaired      bool
+}
+
+// WithDomain compares names as names in domain, so the relative names of
+// records built for CreateDnsRecord, such as "www", or "" and "@" for the
+// domain itself, match the full names ListDnsRecords returns.
+func WithDomain(domain string) Option {
+	return func(d *differ) {
+		d.domain = domain
+	}
+}
+
+// WithNormalization sets which differences do not count. The default is
+// Porkbun.
+func WithNormalization(n Normalization) Option {
+	return func(d *differ) {
+		d.normalization = n
+	}
+}
+
+// WithoutPairing only updates records whose content matches, and creates the
+// other records of after instead of updating leftover records of before to
+// them. It suits adding records next to the ones a name already has, as
+// when importing, where the deletes are then dropped.
+func WithoutPairing() Option {
+	return func(d *differ) {
+		d.unpaired = true
+	}
+}
+
+// Records returns the changes that turn the records before into the records
+// after.
+//
+// Within a record set, records with the same content are matched, and are
+// updated if their TTL or priority differ. The records left over are paired
+// up in order and updated to the new content, as an update keeps the record
+// and takes one call, and the rest are created or deleted; see WithoutPairing.
+// TXT content is compared by value, however it is split into strings.
+//
+// Updates and creates come in the order of after, then deletes in the order
+// of before.
+func Records(before, after []porkbun.Record, options ...Option) []Change {
+	d := &differ{normalization: Porkbun}
+	for _, option := range options {
+		option(d)
+	}
+
+	var keys []string
+	unmatched := map[string][]*porkbun.Record{}
+	for i := range before {
+		record := before[i]
+		k := d.key(record)
+		if _, ok := unmatched[k]; !ok {
+			keys = append(keys, k)
+		}
+		unmatched[k] = append(unmatched[k], &record)
+	}
+
+	var changes []Change
+	var leftover []*porkbun.Record
+	for i := range after {
+		record := after[i]
+		k := d.key(record)
+
+		match := -1
+		for j, candidate := range unmatched[k] {
+			if d.sameContent(*candidate, record) {
+				match = j
+				break
+			}
+		}
+		if match < 0 {
+			leftover = append(leftover, &record)
+			continue
+		}
+
+		candidate := unmatched[k][match]
+		unmatched[k] = append(unmatched[k][:match], unmatched[k][match+1:]...)
+
+		if !d.sameSettings(*candidate, record) {
+			changes = append(changes, Change{Kind: Update, Before: candidate, After: &record})
+		}
+	}
+
+	for _, record := range leftover {
+		k := d.key(*record)
+
+		if d.unpaired || len(unmatched[k]) == 0 {
+			changes = append(changes, Change{Kind: Create, After: record})
+			continue
+		}
+
+		changes = append(changes, Change{Kind: Update, Before: unmatched[k][0], After: record})
+		unmatched[k] = unmatched[k][1:]
+	}
+
+	for _, k := range keys {
+		for _, record := range unmatched[k] {
+			changes = append(changes, Change{Kind: Delete, Before: record})
+		}
+	}
+
+	return changes
+}
+
+// Count returns how many changes there are of each kind.
+func Count(changes []Change) (creates, updates, deletes int) {
+	for _, change := range changes {
+		switch change.Kind {
+		case Create:
+			creates++
+		case Update:
+			updates++
+		case Delete:
+			deletes++
+		}
+	}
+
+	return creates, updates, deletes
+}
+
+// key returns the record set a record belongs to.
+func (d *differ) key(record porkbun.Record) string {
+	return d.name(record.Name) + " " + strings.ToUpper(record.Type)
+}
+
+// name normalizes a record name, and makes it fully qualified if there is a
+// domain to qualify it with.
+func (d *differ) name(name string) string {
+	name = d.host(name)
+
+	domain := d.host(d.domain)
+	if domain == "" || name == domain || strings.HasSuffix(name, "."+domain) {
+		return name
+	}
+
+	if name == "" || name == "@" {
+		return domain
+	}
+
+	return name + "." + domain
+}
+
+// host normalizes a name, or a host name in the content of a record.
+func (d *differ) host(name string) string {
+	if d.normalization.IgnoreTrailingDot && name != "." {
+		name = strings.TrimSuffix(name, ".")
+	}
+	if d.normalization.IgnoreCase {
+		name = strings.ToLower(name)
+	}
+
+	return name
+}
+
+func (d *differ) sameContent(a, b porkbun.Record) bool {
+	switch strings.ToUpper(a.Type) {
+	case "TXT":
+		return a.TextValue() == b.TextValue()
+	case "CNAME", "ALIAS", "NS", "MX":
+		return d.host(a.Content) == d.host(b.Content)
+	case "SRV":
+		// The target comes last, after the weight and the port.
+		fieldsA, fieldsB := strings.Fields(a.Content), strings.Fields(b.Content)
+		if len(fieldsA) != len(fieldsB) || len(fieldsA) == 0 {
+			return a.Content == b.Content
+		}
+
+		last := len(fieldsA) - 1
+		return strings.Join(fieldsA[:last], " ") == strings.Join(fieldsB[:last], " ") &&
+			d.host(fieldsA[last]) == d.host(fieldsB[last])
+	}
+
+	return a.Content == b.Content
+}
+
+// sameSettings compares what there is to a record besides its content.
+func (d *differ) sameSettings(a, b porkbun.Record) bool {
+	if d.ttl(a.TTL) != d.ttl(b.TTL) {
+		return false
+	}
+
+	switch strings.ToUpper(a.Type) {
+	case "MX", "SRV":
+	default:
+		if d.normalization.IgnoreUnusedPriority {
+			return true
+		}
+	}
+
+	return a.Priority == b.Priority
+}
+
+func (d *differ) ttl(ttl porkbun.TTL) porkbun.TTL {
+	return max(ttl, d.normalization.DefaultTTL)
+}
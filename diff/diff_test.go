@@ -0,0 +1,188 @@
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/diff"
+)
+
+// summary is a change reduced to what the tests check.
+type summary struct {
+	kind          diff.Kind
+	before, after string
+}
+
+func summarize(changes []diff.Change) []summary {
+	var summaries []summary
+	for _, change := range changes {
+		s := summary{kind: change.Kind}
+		if change.Before != nil {
+			s.before = change.Before.Id.String()
+		}
+		if change.After != nil {
+			s.after = change.After.Content
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries
+}
+
+func TestRecords(t *testing.T) {
+	live := []porkbun.Record{
+		{Id: "1", Name: "example.com", Type: "A", Content: "192.0.2.1", TTL: 600},
+		{Id: "2", Name: "example.com", Type: "A", Content: "192.0.2.2", TTL: 600},
+		{Id: "3", Name: "www.example.com", Type: "CNAME", Content: "example.com", TTL: 600},
+		{Id: "4", Name: "example.com", Type: "MX", Content: "mail.example.com", TTL: 600, Priority: 10},
+		{Id: "5", Name: "example.com", Type: "TXT", Content: `"v=spf1 " "-all"`, TTL: 600},
+		{Id: "6", Name: "old.example.com", Type: "A", Content: "192.0.2.9", TTL: 600},
+		{Id: "7", Name: "_sip._tcp.example.com", Type: "SRV", Content: "5 5060 sip.example.com", TTL: 600, Priority: 1},
+	}
+
+	testCases := []struct {
+		msg     string
+		desired []porkbun.Record
+		want    []summary
+	}{
+		{
+			msg: "unchanged, however it is written",
+			desired: []porkbun.Record{
+				{Name: "@", Type: "a", Content: "192.0.2.2"},
+				{Name: "", Type: "A", Content: "192.0.2.1", Priority: 5},
+				{Name: "WWW", Type: "CNAME", Content: "Example.com.", TTL: 300},
+				{Name: "", Type: "MX", Content: "mail.example.com.", Priority: 10},
+				{Name: "", Type: "TXT", Content: "v=spf1 -all"},
+				{Name: "old.example.com.", Type: "A", Content: "192.0.2.9"},
+				{Name: "_sip._tcp", Type: "SRV", Content: "5 5060 SIP.example.com.", Priority: 1},
+			},
+		},
+		{
+			msg: "multi-value record set",
+			desired: []porkbun.Record{
+				{Name: "", Type: "A", Content: "192.0.2.2"},
+				{Name: "", Type: "A", Content: "192.0.2.3"},
+				{Name: "", Type: "A", Content: "192.0.2.4"},
+			},
+			want: []summary{
+				{kind: diff.Update, before: "1", after: "192.0.2.3"},
+				{kind: diff.Create, after: "192.0.2.4"},
+				{kind: diff.Delete, before: "3"},
+				{kind: diff.Delete, before: "4"},
+				{kind: diff.Delete, before: "5"},
+				{kind: diff.Delete, before: "6"},
+				{kind: diff.Delete, before: "7"},
+			},
+		},
+		{
+			msg: "ttl and priority",
+			desired: []porkbun.Record{
+				{Name: "", Type: "A", Content: "192.0.2.1", TTL: 3600},
+				{Name: "", Type: "A", Content: "192.0.2.2"},
+				{Name: "www", Type: "CNAME", Content: "example.com"},
+				{Name: "", Type: "MX", Content: "mail.example.com", Priority: 20},
+				{Name: "", Type: "TXT", Content: "v=spf1 -all"},
+				{Name: "old", Type: "A", Content: "192.0.2.9"},
+				{Name: "_sip._tcp", Type: "SRV", Content: "5 5060 sip.example.com", Priority: 1},
+			},
+			want: []summary{
+				{kind: diff.Update, before: "1", after: "192.0.2.1"},
+				{kind: diff.Update, before: "4", after: "mail.example.com"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			got := summarize(diff.Records(live, tc.desired, diff.WithDomain("example.com")))
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("count", func(t *testing.T) {
+		changes := diff.Records(live, testCases[1].desired, diff.WithDomain("example.com"))
+
+		creates, updates, deletes := diff.Count(changes)
+		if creates != 1 || updates != 1 || deletes != 5 {
+			t.Errorf("got %d, %d, %d, want 1, 1, 5", creates, updates, deletes)
+		}
+	})
+}
+
+func TestWithoutPairing(t *testing.T) {
+	live := []porkbun.Record{
+		{Id: "1", Name: "example.com", Type: "TXT", Content: "google-site-verification=abc", TTL: 600},
+		{Id: "2", Name: "example.com", Type: "TXT", Content: "v=spf1 mx -all", TTL: 600},
+		{Id: "3", Name: "example.com", Type: "A", Content: "192.0.2.1", TTL: 600},
+	}
+	desired := []porkbun.Record{
+		{Name: "", Type: "TXT", Content: "v=spf1 include:_spf.example.net -all"},
+		{Name: "", Type: "TXT", Content: "v=spf1 mx -all", TTL: 3600},
+		{Name: "", Type: "A", Content: "192.0.2.2"},
+	}
+
+	got := summarize(diff.Records(live, desired, diff.WithDomain("example.com"), diff.WithoutPairing()))
+	want := []summary{
+		{kind: diff.Update, before: "2", after: "v=spf1 mx -all"},
+		{kind: diff.Create, after: "v=spf1 include:_spf.example.net -all"},
+		{kind: diff.Create, after: "192.0.2.2"},
+		{kind: diff.Delete, before: "1"},
+		{kind: diff.Delete, before: "3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestNormalization(t *testing.T) {
+	before := []porkbun.Record{{Id: "1", Name: "WWW.example.com.", Type: "CNAME", Content: "Example.com.", Priority: 5}}
+	after := []porkbun.Record{{Name: "www.example.com", Type: "CNAME", Content: "example.com", TTL: 600}}
+
+	testCases := []struct {
+		msg           string
+		normalization diff.Normalization
+		want          []summary
+	}{
+		{
+			msg:           "porkbun",
+			normalization: diff.Porkbun,
+		},
+		{
+			msg:           "exact",
+			normalization: diff.Normalization{},
+			want: []summary{
+				{kind: diff.Create, after: "example.com"},
+				{kind: diff.Delete, before: "1"},
+			},
+		},
+		{
+			msg:           "case matters",
+			normalization: diff.Normalization{IgnoreTrailingDot: true, DefaultTTL: 600, IgnoreUnusedPriority: true},
+			want: []summary{
+				{kind: diff.Create, after: "example.com"},
+				{kind: diff.Delete, before: "1"},
+			},
+		},
+		{
+			msg:           "ttl and priority matter",
+			normalization: diff.Normalization{IgnoreTrailingDot: true, IgnoreCase: true},
+			want: []summary{
+				{kind: diff.Update, before: "1", after: "example.com"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			got := summarize(diff.Records(before, after, diff.WithNormalization(tc.normalization)))
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Package dkim generates DKIM keys and builds, parses and retires the TXT
+// records that publish them.
+//
+// A rotation publishes a new selector, moves mail signing over to it, and
+// removes the old selector once mail signed with it has been delivered.
+// Retire marks the old record with the time it may be removed, in its n=
+// tag, and Expired finds it again later, so the removal can happen in a later
+// run.
+package dkim
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// The name under which selectors are published.
+const domainKey = "_domainkey"
+
+// The prefix of the n= tag of a retired record.
+const retirePrefix = "retire-after-"
+
+// The layout of the time in the n= tag. The tag is free text, but colons and
+// spaces would need encoding.
+const retireLayout = "20060102T150405Z"
+
+type Algorithm string
+
+const (
+	RSA     Algorithm = "rsa"
+	Ed25519 Algorithm = "ed25519"
+)
+
+// The size of generated RSA keys. Shorter keys are considered weak.
+const RSABits = 2048
+
+// Key is a DKIM signing key.
+type Key struct {
+	Algorithm Algorithm
+	Signer    crypto.Signer
+}
+
+// GenerateKey creates an RSA-2048 or Ed25519 key.
+func GenerateKey(algorithm Algorithm) (*Key, error) {
+	var signer crypto.Signer
+	var err error
+
+	switch algorithm {
+	case RSA:
+		signer, err = rsa.GenerateKey(rand.Reader, RSABits)
+	case Ed25519:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q, want %q or %q", algorithm, RSA, Ed25519)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err generating %s key, %w", algorithm, err)
+	}
+
+	return &Key{Algorithm: algorithm, Signer: signer}, nil
+}
+
+// PrivateKeyPEM encodes the private key as PKCS #8, for the mail server that
+// signs with it.
+func (k *Key) PrivateKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("err encoding private key, %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicKey returns the p= value: the DER public key for RSA, and the raw 32
+// bytes for Ed25519, as RFC 8463 has it.
+func (k *Key) PublicKey() ([]byte, error) {
+	switch public := k.Signer.Public().(type) {
+	case ed25519.PublicKey:
+		return public, nil
+	default:
+		der, err := x509.MarshalPKIXPublicKey(public)
+		if err != nil {
+			return nil, fmt.Errorf("err encoding public key, %w", err)
+		}
+		return der, nil
+	}
+}
+
+// Record builds the TXT record publishing the key at selector, under the
+// subdomain name; empty is the apex. Content too long for one TXT string is
+// split with porkbun.SplitText.
+func (k *Key) Record(selector, name string) (porkbun.Record, error) {
+	public, err := k.PublicKey()
+	if err != nil {
+		return porkbun.Record{}, err
+	}
+
+	record := &Record{}
+	record.Set("v", "DKIM1")
+	record.Set("k", string(k.Algorithm))
+	record.Set("p", base64.StdEncoding.EncodeToString(public))
+
+	return porkbun.Record{
+		Name:    RecordName(selector, name),
+		Type:    "TXT",
+		Content: porkbun.SplitText(record.String()),
+	}, nil
+}
+
+// RecordName returns the name of a selector's record under the subdomain
+// name; empty is the apex.
+func RecordName(selector, name string) string {
+	if name == "" || name == "@" {
+		return selector + "." + domainKey
+	}
+
+	return selector + "." + domainKey + "." + name
+}
+
+// Selector returns the selector of a record name, as returned by Porkbun, and
+// whether it is a DKIM record name at all.
+func Selector(name string) (string, bool) {
+	selector, rest, ok := strings.Cut(name, "."+domainKey)
+	if !ok || selector == "" || (rest != "" && !strings.HasPrefix(rest, ".")) {
+		return "", false
+	}
+
+	return selector, true
+}
+
+// Tag is a name=value pair of a DKIM record.
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Record is a parsed DKIM key record. Tags keep their order, so a record
+// can be changed and written back.
+type Record struct {
+	Tags []Tag
+}
+
+// Parse reads the content of a DKIM TXT record. Content split into quoted
+// strings is joined first.
+func Parse(content string) (*Record, error) {
+	record := &Record{}
+	for _, part := range strings.Split(porkbun.JoinText(content), ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("tag %q has no value", part)
+		}
+		record.Tags = append(record.Tags, Tag{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+
+	if v, ok := record.Get("v"); ok && v != "DKIM1" {
+		return nil, fmt.Errorf("unknown version %q", v)
+	}
+	if _, ok := record.Get("p"); !ok {
+		return nil, fmt.Errorf("no p= tag")
+	}
+
+	return record, nil
+}
+
+// Get returns the value of a tag.
+func (r *Record) Get(name string) (string, bool) {
+	for _, tag := range r.Tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set changes the value of a tag, or adds it at the end.
+func (r *Record) Set(name, value string) {
+	for i := range r.Tags {
+		if r.Tags[i].Name == name {
+			r.Tags[i].Value = value
+			return
+		}
+	}
+
+	r.Tags = append(r.Tags, Tag{Name: name, Value: value})
+}
+
+func (r *Record) String() string {
+	tags := make([]string, len(r.Tags))
+	for i, tag := range r.Tags {
+		tags[i] = tag.Name + "=" + tag.Value
+	}
+
+	return strings.Join(tags, "; ")
+}
+
+// KeyType returns the k= tag, which defaults to rsa.
+func (r *Record) KeyType() Algorithm {
+	if k, ok := r.Get("k"); ok {
+		return Algorithm(strings.ToLower(k))
+	}
+
+	return RSA
+}
+
+// PublicKey decodes the p= tag. It is empty for a revoked key.
+func (r *Record) PublicKey() ([]byte, error) {
+	p, _ := r.Get("p")
+	p = strings.Join(strings.Fields(p), "")
+
+	return base64.StdEncoding.DecodeString(p)
+}
+
+// Bits returns the size of the public key, in bits, or 0 for a revoked key.
+func (r *Record) Bits() (int, error) {
+	public, err := r.PublicKey()
+	if err != nil || len(public) == 0 {
+		return 0, err
+	}
+
+	switch r.KeyType() {
+	case Ed25519:
+		return len(public) * 8, nil
+	case RSA:
+		key, err := x509.ParsePKIXPublicKey(public)
+		if err != nil {
+			// Some records hold a bare PKCS #1 key.
+			if key, err = x509.ParsePKCS1PublicKey(public); err != nil {
+				return 0, fmt.Errorf("err parsing rsa key, %w", err)
+			}
+		}
+
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return 0, fmt.Errorf("k=rsa holds a %T", key)
+		}
+		return rsaKey.N.BitLen(), nil
+	default:
+		return 0, fmt.Errorf("unknown key type %q", r.KeyType())
+	}
+}
+
+// Retire marks the record to be removed after the given time, in its n= tag.
+// It returns the new content for the record, split like Key.Record.
+func Retire(record porkbun.Record, after time.Time) (string, error) {
+	parsed, err := Parse(record.Content)
+	if err != nil {
+		return "", fmt.Errorf("err parsing dkim record %q, %w", record.Name, err)
+	}
+
+	parsed.Set("n", retirePrefix+after.UTC().Format(retireLayout))
+
+	return porkbun.SplitText(parsed.String()), nil
+}
+
+// RetireAfter returns when a record marked by Retire may be removed.
+func RetireAfter(record porkbun.Record) (time.Time, bool) {
+	parsed, err := Parse(record.Content)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	notes, _ := parsed.Get("n")
+	if !strings.HasPrefix(notes, retirePrefix) {
+		return time.Time{}, false
+	}
+
+	after, err := time.Parse(retireLayout, strings.TrimPrefix(notes, retirePrefix))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return after, true
+}
+
+// Expired returns the DKIM records that were retired before now.
+func Expired(records []porkbun.Record, now time.Time) []porkbun.Record {
+	var expired []porkbun.Record
+	for _, record := range records {
+		if !strings.EqualFold(record.Type, "TXT") {
+			continue
+		}
+		if _, ok := Selector(record.Name); !ok {
+			continue
+		}
+
+		if after, ok := RetireAfter(record); ok && !now.Before(after) {
+			expired = append(expired, record)
+		}
+	}
+
+	return expired
+}
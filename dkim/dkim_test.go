@@ -0,0 +1,181 @@
+package dkim_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/dkim"
+)
+
+func TestGenerateKey(t *testing.T) {
+	testCases := []struct {
+		algorithm dkim.Algorithm
+		bits      int
+		chunked   bool
+	}{
+		{algorithm: dkim.RSA, bits: 2048, chunked: true},
+		{algorithm: dkim.Ed25519, bits: 256, chunked: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.algorithm), func(t *testing.T) {
+			key, err := dkim.GenerateKey(tc.algorithm)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			privatePEM, err := key.PrivateKeyPEM()
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			block, _ := pem.Decode(privatePEM)
+			if block == nil || block.Type != "PRIVATE KEY" {
+				t.Fatalf("got %s, want a PRIVATE KEY block", privatePEM)
+			}
+			if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+				t.Errorf("got %s, want nil", err)
+			}
+
+			record, err := key.Record("s2", "mail")
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if record.Name != "s2._domainkey.mail" || record.Type != "TXT" {
+				t.Errorf("got %q %q, want %q %q", record.Name, record.Type, "s2._domainkey.mail", "TXT")
+			}
+
+			if chunked := strings.HasPrefix(record.Content, `"`); chunked != tc.chunked {
+				t.Errorf("got chunked %t, want %t for %s", chunked, tc.chunked, record.Content)
+			}
+
+			if err := record.Validate(); err != nil {
+				t.Errorf("got %s, want nil", err)
+			}
+
+			parsed, err := dkim.Parse(record.Content)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if parsed.KeyType() != tc.algorithm {
+				t.Errorf("got %s, want %s", parsed.KeyType(), tc.algorithm)
+			}
+
+			bits, err := parsed.Bits()
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+			if bits != tc.bits {
+				t.Errorf("got %d bits, want %d", bits, tc.bits)
+			}
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := dkim.GenerateKey("dsa"); err == nil {
+			t.Error("got nil, want an error")
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	// A 1024 bit key, as still published by many providers.
+	const short = "MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDDsZ8yBPhcTY4bkoJpRv0vqySOESjfSexVAXAzrBqPUxSrjQMagCAlU2EvlWpc6TlN8tKVAE4g8HCuU2Qa3WV/PMZcbFBN4g3dpM0H+DUt0l0vvDyh1IeJ7/vDRgcnOcvfYGu9EQmMUJ+SCeMRwTeO+Wtre/iMm0JZkNZqnFvjOQIDAQAB"
+
+	record, err := dkim.Parse(`"v=DKIM1; k=rsa; t=s; p=` + short[:100] + `" "` + short[100:] + `"`)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if flags, _ := record.Get("t"); flags != "s" {
+		t.Errorf("got t=%q, want %q", flags, "s")
+	}
+
+	if bits, err := record.Bits(); err != nil || bits != 1024 {
+		t.Errorf("got %d %v, want 1024 nil", bits, err)
+	}
+
+	revoked, err := dkim.Parse("v=DKIM1; p=")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if bits, err := revoked.Bits(); err != nil || bits != 0 {
+		t.Errorf("got %d %v, want 0 nil", bits, err)
+	}
+
+	for _, content := range []string{"v=DKIM2; p=abc", "v=DKIM1; k=rsa", "v=DKIM1; p"} {
+		if _, err := dkim.Parse(content); err == nil {
+			t.Errorf("got nil, want an error for %q", content)
+		}
+	}
+}
+
+func TestRetire(t *testing.T) {
+	key, err := dkim.GenerateKey(dkim.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := key.Record("s1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Name = "s1._domainkey.example.com"
+
+	after := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	record.Content, err = dkim.Retire(record, after)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if err := record.Validate(); err != nil {
+		t.Errorf("got %s, want nil", err)
+	}
+
+	got, ok := dkim.RetireAfter(record)
+	if !ok || !got.Equal(after) {
+		t.Errorf("got %s %t, want %s true", got, ok, after)
+	}
+
+	records := []porkbun.Record{
+		record,
+		{Name: "s2._domainkey.example.com", Type: "TXT", Content: "v=DKIM1; p=abc"},
+		{Name: "example.com", Type: "TXT", Content: "v=DKIM1; n=retire-after-20240101T000000Z; p="},
+	}
+
+	if expired := dkim.Expired(records, after.Add(-time.Second)); len(expired) != 0 {
+		t.Errorf("got %d expired records, want 0", len(expired))
+	}
+
+	expired := dkim.Expired(records, after)
+	if len(expired) != 1 || expired[0].Name != record.Name {
+		t.Errorf("got %+v, want only %q", expired, record.Name)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	testCases := []struct {
+		name     string
+		selector string
+		ok       bool
+	}{
+		{name: "s1._domainkey.example.com", selector: "s1", ok: true},
+		{name: "s1._domainkey", selector: "s1", ok: true},
+		{name: "_domainkey.example.com", ok: false},
+		{name: "s1._domainkeys.example.com", ok: false},
+		{name: "www.example.com", ok: false},
+	}
+
+	for _, tc := range testCases {
+		selector, ok := dkim.Selector(tc.name)
+		if selector != tc.selector || ok != tc.ok {
+			t.Errorf("got %q %t, want %q %t for %q", selector, ok, tc.selector, tc.ok, tc.name)
+		}
+	}
+}
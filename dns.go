@@ -3,8 +3,8 @@ package porkbun
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 )
 
 type ApiError struct {
@@ -16,9 +16,42 @@ func (e *ApiError) Error() string {
 	return fmt.Sprintf("API error %d: %s", e.Code, e.Body)
 }
 
+// ErrConflict is matched by a *ConflictError with errors.Is.
+var ErrConflict = errors.New("dns record changed")
+
+// ConflictError is returned by ModifyDnsRecordIf when the record no longer
+// holds what the caller expected.
+type ConflictError struct {
+	Expected Record
+
+	// The record as it is now, or nil if it no longer exists.
+	Actual *Record
+}
+
+func (e *ConflictError) Error() string {
+	if e.Actual == nil {
+		return fmt.Sprintf("%s: %q %q with content %q not found", ErrConflict, e.Expected.Name, e.Expected.Type, e.Expected.Content)
+	}
+
+	return fmt.Sprintf(
+		"%s: expected content %q ttl %d priority %d, found %q %d %d",
+		ErrConflict,
+		e.Expected.Content,
+		e.Expected.TTL,
+		e.Expected.Priority,
+		e.Actual.Content,
+		e.Actual.TTL,
+		e.Actual.Priority,
+	)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
 type Record struct {
-	Id    string `json:"id"`
-	Notes string `json:"notes"`
+	Id    RecordID `json:"id"`
+	Notes string   `json:"notes"`
 
 	// The subdomain for the record being created, not including the domain
 	// itself. Leave blank to create a record on the root domain. Use * to
@@ -36,10 +69,10 @@ type Record struct {
 
 	// The time to live in seconds for the record. The minimum and the default
 	// is 600 seconds. Optional.
-	TTL string `json:"ttl"`
+	TTL TTL `json:"ttl,omitempty"`
 
 	// The priority of the record for those that support it. Optional.
-	Priority string `json:"prio"`
+	Priority Priority `json:"prio,omitempty"`
 }
 
 type CreateDnsRecordResponse struct {
@@ -47,11 +80,9 @@ type CreateDnsRecordResponse struct {
 	// processed.
 	Status string `json:"status"`
 
-	// The Id of the record created.
-	//
-	// Creating a record, the Id returned is an int, but every other method
-	// expects Id to be a string. This is accomodating for the upstream API...
-	Id int `json:"id"`
+	// The Id of the record created. Porkbun returns it as a number here, and
+	// as a string everywhere else; RecordID accepts both.
+	Id RecordID `json:"id"`
 }
 
 type DnsRecordsResponse struct {
@@ -63,10 +94,22 @@ type StatusResponse struct {
 	Status string `json:"status"`
 }
 
-// CreateDnsRecord creates a DNS entry in Porkbun.
+// CreateDnsRecord creates a DNS entry in Porkbun. The record is checked with
+// Validate first, unless the client was made WithoutValidation. Bare TXT
+// content too long for one string is split with SplitText.
 //
 // https://porkbun.com/api/json/v3/documentation#DNS%20Create%20Record
 func (c *Client) CreateDnsRecord(ctx context.Context, domain string, params *Record) (*CreateDnsRecordResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err = c.prepare(params)
+	if err != nil {
+		return nil, err
+	}
+
 	reqBody, err := json.Marshal(params)
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal params, %w", err)
@@ -87,19 +130,10 @@ func (c *Client) CreateDnsRecord(ctx context.Context, domain string, params *Rec
 			err,
 		)
 	}
+	defer res.Body.Close()
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		// Read response body
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		// Return custom error
-		return nil, &ApiError{
-			Code: res.StatusCode,
-			Body: string(body),
-		}
+	if err := checkResponse(res); err != nil {
+		return nil, err
 	}
 
 	var response CreateDnsRecordResponse
@@ -115,6 +149,16 @@ func (c *Client) CreateDnsRecord(ctx context.Context, domain string, params *Rec
 // Get all available records by leaving the subdomain and recordType as empty.
 // Find a subset of records by providing the subdomain and type.
 func (c *Client) ListDnsRecords(ctx context.Context, domain, subdomain, recordType string) (*DnsRecordsResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	subdomain, err = ToASCII(subdomain)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err := c.withAuthentication(nil)
 	if err != nil {
 		return nil, fmt.Errorf("err adding authentication, %w", err)
@@ -133,6 +177,10 @@ func (c *Client) ListDnsRecords(ctx context.Context, domain, subdomain, recordTy
 	}
 	defer res.Body.Close()
 
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
 	var response DnsRecordsResponse
 	decoder := json.NewDecoder(res.Body)
 	if err := decoder.Decode(&response); err != nil {
@@ -142,18 +190,27 @@ func (c *Client) ListDnsRecords(ctx context.Context, domain, subdomain, recordTy
 	return &response, nil
 }
 
-func (c *Client) GetDnsRecordById(ctx context.Context, domain string, id int) (*DnsRecordsResponse, error) {
+func (c *Client) GetDnsRecordById(ctx context.Context, domain string, id RecordID) (*DnsRecordsResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err := c.withAuthentication(nil)
 	if err != nil {
 		return nil, fmt.Errorf("err adding authentication, %w", err)
 	}
 
-	res, err := c.do(ctx, fmt.Sprintf("/api/json/v3/dns/retrieve/%s/%d", domain, id), body)
+	res, err := c.do(ctx, fmt.Sprintf("/api/json/v3/dns/retrieve/%s/%s", domain, id), body)
 	if err != nil {
 		return nil, fmt.Errorf("err retrieving dns record, %w", err)
 	}
 	defer res.Body.Close()
 
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
 	var response DnsRecordsResponse
 	decoder := json.NewDecoder(res.Body)
 	if err := decoder.Decode(&response); err != nil {
@@ -165,13 +222,25 @@ func (c *Client) GetDnsRecordById(ctx context.Context, domain string, id int) (*
 
 // ModifyDnsRecord changes a DNS entry in Porkbun.
 //
-// Only Content, TTL, and Priority are necessary fields on the record.
+// Only Content, TTL, and Priority are necessary fields on the record. As with
+// CreateDnsRecord, the record is checked with Validate first, and long TXT
+// content is split.
 //
 // If record.Id is not empty, then modify a record found by the provided ID.
 // Otherwise, the record will be looked up by the subdomain and type.
 //
 // https://porkbun.com/api/json/v3/documentation#DNS%20Edit%20Record%20by%20Domain%20and%20ID
 func (c *Client) ModifyDnsRecord(ctx context.Context, domain string, record *Record) (*StatusResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err = c.prepare(record)
+	if err != nil {
+		return nil, err
+	}
+
 	reqBody, err := json.Marshal(record)
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal record, %w", err)
@@ -203,19 +272,10 @@ func (c *Client) ModifyDnsRecord(ctx context.Context, domain string, record *Rec
 			err,
 		)
 	}
+	defer res.Body.Close()
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		// Read response body
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		// Return custom error
-		return nil, &ApiError{
-			Code: res.StatusCode,
-			Body: string(body),
-		}
+	if err := checkResponse(res); err != nil {
+		return nil, err
 	}
 
 	var response StatusResponse
@@ -227,10 +287,82 @@ func (c *Client) ModifyDnsRecord(ctx context.Context, domain string, record *Rec
 	return &response, nil
 }
 
+// ModifyDnsRecordIf changes a DNS entry in Porkbun, but only if it still holds
+// what the caller expects, so concurrent writers don't silently overwrite each
+// other.
+//
+// The record is read again by expected.Id, or by expected.Name and
+// expected.Type. If none holds the expected content, TTL and priority, a
+// *ConflictError is returned and nothing is changed. Empty fields in expected
+// are not compared. Otherwise, the matching record is modified by id to
+// desired, which needs the Name, Type and Content of the whole record.
+//
+// Porkbun has no conditional edit, so a change made between the read and the
+// edit can still be overwritten; the window is just much smaller.
+func (c *Client) ModifyDnsRecordIf(ctx context.Context, domain string, expected, desired *Record) (*StatusResponse, error) {
+	var current *DnsRecordsResponse
+	var err error
+
+	if expected.Id != "" {
+		current, err = c.GetDnsRecordById(ctx, domain, expected.Id)
+	} else {
+		if expected.Type == "" {
+			return nil, fmt.Errorf("expected.Type must be set to modify this entry")
+		}
+		current, err = c.ListDnsRecords(ctx, domain, expected.Name, expected.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err reading dns record, %w", err)
+	}
+
+	var match *Record
+	for i := range current.Records {
+		if matchesExpected(&current.Records[i], expected) {
+			match = &current.Records[i]
+			break
+		}
+	}
+
+	if match == nil {
+		conflict := &ConflictError{Expected: *expected}
+		if len(current.Records) == 1 {
+			conflict.Actual = &current.Records[0]
+		}
+
+		return nil, conflict
+	}
+
+	edit := *desired
+	edit.Id = match.Id
+
+	return c.ModifyDnsRecord(ctx, domain, &edit)
+}
+
+func matchesExpected(record, expected *Record) bool {
+	if expected.Content != "" && !sameContent(record.Type, record.Content, expected.Content) {
+		return false
+	}
+
+	if expected.TTL != 0 && !sameTTL(record.TTL, expected.TTL) {
+		return false
+	}
+
+	if expected.Priority != 0 && record.Priority != expected.Priority {
+		return false
+	}
+
+	return true
+}
+
 // DeleteDnsRecordById deletes a DNS entry in Porkbun, looking up by id.
 //
 // https://porkbun.com/api/json/v3/documentation#DNS%20Delete%20Record%20by%20Domain%20and%20ID
-func (c *Client) DeleteDnsRecordById(ctx context.Context, domain, id string) (*StatusResponse, error) {
+func (c *Client) DeleteDnsRecordById(ctx context.Context, domain string, id RecordID) (*StatusResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err := c.withAuthentication(nil)
 	if err != nil {
 		return nil, fmt.Errorf("err adding authentication, %w", err)
@@ -240,19 +372,10 @@ func (c *Client) DeleteDnsRecordById(ctx context.Context, domain, id string) (*S
 	if err != nil {
 		return nil, fmt.Errorf("err deleting dns record %q, %w", id, err)
 	}
+	defer res.Body.Close()
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		// Read response body
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		// Return custom error
-		return nil, &ApiError{
-			Code: res.StatusCode,
-			Body: string(body),
-		}
+	if err := checkResponse(res); err != nil {
+		return nil, err
 	}
 
 	var response StatusResponse
@@ -268,6 +391,16 @@ func (c *Client) DeleteDnsRecordById(ctx context.Context, domain, id string) (*S
 //
 // https://porkbun.com/api/json/v3/documentation#DNS%20Delete%20Records%20by%20Domain,%20Subdomain%20and%20Type
 func (c *Client) DeleteDnsRecordByLookup(ctx context.Context, domain, subdomain, recordType string) (*StatusResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	subdomain, err = ToASCII(subdomain)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err := c.withAuthentication(nil)
 	if err != nil {
 		return nil, fmt.Errorf("err adding authentication, %w", err)
@@ -277,19 +410,10 @@ func (c *Client) DeleteDnsRecordByLookup(ctx context.Context, domain, subdomain,
 	if err != nil {
 		return nil, fmt.Errorf("err deleting dns record %q, %q, %w", subdomain, recordType, err)
 	}
+	defer res.Body.Close()
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		// Read response body
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		// Return custom error
-		return nil, &ApiError{
-			Code: res.StatusCode,
-			Body: string(body),
-		}
+	if err := checkResponse(res); err != nil {
+		return nil, err
 	}
 
 	var response StatusResponse
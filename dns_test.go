@@ -2,12 +2,14 @@ package porkbun_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
 )
 
 func TestDns(t *testing.T) {
@@ -38,8 +40,104 @@ func TestDns(t *testing.T) {
 			t.Errorf("got %s, want %s", res.Status, "SUCCESS")
 		}
 
-		if res.Id != 1234 {
-			t.Errorf("got %d, want %d", res.Id, 1234)
+		if res.Id != "1234" {
+			t.Errorf("got %s, want %s", res.Id, "1234")
+		}
+	})
+}
+
+func TestModifyDnsRecordIf(t *testing.T) {
+	ctx := context.TODO()
+
+	testCases := []struct {
+		msg      string
+		expected porkbun.Record
+		conflict bool
+	}{
+		{
+			msg:      "by name and type",
+			expected: porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2"},
+		},
+		{
+			msg:      "by ttl",
+			expected: porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2", TTL: 600},
+		},
+		{
+			msg:      "changed content",
+			expected: porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.9"},
+			conflict: true,
+		},
+		{
+			msg:      "changed ttl",
+			expected: porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2", TTL: 3600},
+			conflict: true,
+		},
+		{
+			msg:      "deleted",
+			expected: porkbun.Record{Name: "mail", Type: "A", Content: "10.0.0.2"},
+			conflict: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			server := porkbuntest.NewServer()
+			defer server.Close()
+			server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"})
+			server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2"})
+
+			_, err := newTestClient(t, server).ModifyDnsRecordIf(ctx, "example.com", &tc.expected, &porkbun.Record{
+				Name:    "www",
+				Type:    "A",
+				Content: "10.0.0.3",
+			})
+
+			records := server.Backend.Records("example.com")
+
+			if tc.conflict {
+				if !errors.Is(err, porkbun.ErrConflict) {
+					t.Fatalf("got %v, want %v", err, porkbun.ErrConflict)
+				}
+
+				var conflict *porkbun.ConflictError
+				if !errors.As(err, &conflict) || conflict.Expected != tc.expected {
+					t.Errorf("got %v, want a ConflictError", err)
+				}
+
+				if records[1].Content != "10.0.0.2" {
+					t.Errorf("got %s, want record unchanged", records[1].Content)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if records[0].Content != "10.0.0.1" || records[1].Content != "10.0.0.3" {
+				t.Errorf("got %+v, want only the expected record modified", records)
+			}
+		})
+	}
+
+	t.Run("by id", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		id, _ := server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"})
+
+		_, err := newTestClient(t, server).ModifyDnsRecordIf(
+			ctx,
+			"example.com",
+			&porkbun.Record{Id: id, Content: "10.0.0.9"},
+			&porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"},
+		)
+
+		var conflict *porkbun.ConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("got %v, want a ConflictError", err)
+		}
+
+		if conflict.Actual == nil || conflict.Actual.Content != "10.0.0.1" {
+			t.Errorf("got %+v, want the current record", conflict.Actual)
 		}
 	})
 }
@@ -0,0 +1,299 @@
+package porkbun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// The listAll endpoint returns domains in pages of this size.
+const domainPageSize = 1000
+
+type Domain struct {
+	Domain       string `json:"domain"`
+	Status       string `json:"status"`
+	TLD          string `json:"tld"`
+	CreateDate   string `json:"createDate"`
+	ExpireDate   string `json:"expireDate"`
+	SecurityLock string `json:"securityLock"`
+	WhoisPrivacy string `json:"whoisPrivacy"`
+	AutoRenew    int    `json:"autoRenew"`
+	NotLocal     int    `json:"notLocal"`
+}
+
+type DomainsResponse struct {
+	Status  string   `json:"status"`
+	Domains []Domain `json:"domains"`
+}
+
+type NameServersResponse struct {
+	Status      string   `json:"status"`
+	NameServers []string `json:"ns"`
+}
+
+type UrlForward struct {
+	Id string `json:"id,omitempty"`
+
+	// The subdomain to forward, not including the domain itself. Leave blank
+	// to forward the root domain.
+	Subdomain string `json:"subdomain"`
+
+	// Where to forward to.
+	Location string `json:"location"`
+
+	// The type of forward. Valid types are: temporary, permanent.
+	Type string `json:"type"`
+
+	// Whether to append the URI path to the location. Valid values are: yes,
+	// no.
+	IncludePath string `json:"includePath"`
+
+	// Whether to also forward all subdomains. Valid values are: yes, no.
+	Wildcard string `json:"wildcard"`
+}
+
+type UrlForwardsResponse struct {
+	Status   string       `json:"status"`
+	Forwards []UrlForward `json:"forwards"`
+}
+
+// ListDomains returns every domain in the account, requesting each page of
+// results in turn.
+//
+// https://porkbun.com/api/json/v3/documentation#Domain%20List%20All
+func (c *Client) ListDomains(ctx context.Context) (*DomainsResponse, error) {
+	response := DomainsResponse{Domains: []Domain{}}
+
+	for start := 0; ; start += domainPageSize {
+		page, err := c.listDomainsPage(ctx, start)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Status = page.Status
+		response.Domains = append(response.Domains, page.Domains...)
+
+		if len(page.Domains) < domainPageSize {
+			return &response, nil
+		}
+	}
+}
+
+func (c *Client) listDomainsPage(ctx context.Context, start int) (*DomainsResponse, error) {
+	reqBody, err := json.Marshal(map[string]string{"start": strconv.Itoa(start)})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal params, %w", err)
+	}
+
+	body, err := c.withAuthentication(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("err adding authentication, %w", err)
+	}
+
+	res, err := c.do(ctx, "/api/json/v3/domain/listAll", body)
+	if err != nil {
+		return nil, fmt.Errorf("err listing domains, %w", err)
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	var response DomainsResponse
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response body, %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetNameServers returns the authoritative name servers of a domain.
+//
+// https://porkbun.com/api/json/v3/documentation#Domain%20Get%20Name%20Servers
+func (c *Client) GetNameServers(ctx context.Context, domain string) (*NameServersResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.withAuthentication(nil)
+	if err != nil {
+		return nil, fmt.Errorf("err adding authentication, %w", err)
+	}
+
+	res, err := c.do(ctx, fmt.Sprintf("/api/json/v3/domain/getNs/%s", domain), body)
+	if err != nil {
+		return nil, fmt.Errorf("err getting name servers, %w", err)
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	var response NameServersResponse
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response body, %w", err)
+	}
+
+	return &response, nil
+}
+
+// UpdateNameServers replaces the authoritative name servers of a domain.
+//
+// https://porkbun.com/api/json/v3/documentation#Domain%20Update%20Name%20Servers
+func (c *Client) UpdateNameServers(ctx context.Context, domain string, nameServers []string) (*StatusResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	ascii := make([]string, len(nameServers))
+	for i, ns := range nameServers {
+		if ascii[i], err = ToASCII(ns); err != nil {
+			return nil, err
+		}
+	}
+
+	reqBody, err := json.Marshal(map[string][]string{"ns": ascii})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal params, %w", err)
+	}
+
+	body, err := c.withAuthentication(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("err adding authentication, %w", err)
+	}
+
+	res, err := c.do(ctx, fmt.Sprintf("/api/json/v3/domain/updateNs/%s", domain), body)
+	if err != nil {
+		return nil, fmt.Errorf("err updating name servers, %w", err)
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	var response StatusResponse
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response body, %w", err)
+	}
+
+	return &response, nil
+}
+
+// AddUrlForward forwards a domain or subdomain to another location. The Id of
+// the forward is ignored.
+//
+// https://porkbun.com/api/json/v3/documentation#Domain%20Add%20URL%20Forward
+func (c *Client) AddUrlForward(ctx context.Context, domain string, forward *UrlForward) (*StatusResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	asciiForward := *forward
+	if asciiForward.Subdomain, err = ToASCII(forward.Subdomain); err != nil {
+		return nil, err
+	}
+
+	reqBody, err := json.Marshal(asciiForward)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal forward, %w", err)
+	}
+
+	body, err := c.withAuthentication(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("err adding authentication, %w", err)
+	}
+
+	res, err := c.do(ctx, fmt.Sprintf("/api/json/v3/domain/addUrlForward/%s", domain), body)
+	if err != nil {
+		return nil, fmt.Errorf("err adding url forward %q %q, %w", forward.Subdomain, forward.Location, err)
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	var response StatusResponse
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response body, %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetUrlForwarding returns the URL forwards of a domain.
+//
+// https://porkbun.com/api/json/v3/documentation#Domain%20Get%20URL%20Forwarding
+func (c *Client) GetUrlForwarding(ctx context.Context, domain string) (*UrlForwardsResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.withAuthentication(nil)
+	if err != nil {
+		return nil, fmt.Errorf("err adding authentication, %w", err)
+	}
+
+	res, err := c.do(ctx, fmt.Sprintf("/api/json/v3/domain/getUrlForwarding/%s", domain), body)
+	if err != nil {
+		return nil, fmt.Errorf("err getting url forwards, %w", err)
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	var response UrlForwardsResponse
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response body, %w", err)
+	}
+
+	return &response, nil
+}
+
+// DeleteUrlForward deletes a URL forward from a domain by id.
+//
+// https://porkbun.com/api/json/v3/documentation#Domain%20Delete%20URL%20Forward
+func (c *Client) DeleteUrlForward(ctx context.Context, domain, id string) (*StatusResponse, error) {
+	domain, err := ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.withAuthentication(nil)
+	if err != nil {
+		return nil, fmt.Errorf("err adding authentication, %w", err)
+	}
+
+	res, err := c.do(ctx, fmt.Sprintf("/api/json/v3/domain/deleteUrlForward/%s/%s", domain, id), body)
+	if err != nil {
+		return nil, fmt.Errorf("err deleting url forward %q, %w", id, err)
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	var response StatusResponse
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response body, %w", err)
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,106 @@
+package porkbun_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func newTestClient(t *testing.T, server *porkbuntest.Server) *porkbun.Client {
+	t.Helper()
+
+	client, err := porkbun.NewClient(
+		porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+		porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+		porkbun.WithBaseUrl(server.URL),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestDomain(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("list", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+
+		// More than a page, so every page has to be requested.
+		for i := 0; i < 1001; i++ {
+			server.Backend.AddDomain(fmt.Sprintf("example%04d.com", i))
+		}
+
+		res, err := newTestClient(t, server).ListDomains(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if len(res.Domains) != 1001 {
+			t.Errorf("got %d domains, want %d", len(res.Domains), 1001)
+		}
+
+		if res.Domains[1000].Domain != "example1000.com" {
+			t.Errorf("got %s, want %s", res.Domains[1000].Domain, "example1000.com")
+		}
+	})
+
+	t.Run("name servers", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+		defer server.Close()
+		client := newTestClient(t, server)
+
+		want := []string{"ns1.example.net", "ns2.example.net"}
+		if _, err := client.UpdateNameServers(ctx, "example.com", want); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		res, err := client.GetNameServers(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if len(res.NameServers) != 2 || res.NameServers[0] != want[0] || res.NameServers[1] != want[1] {
+			t.Errorf("got %v, want %v", res.NameServers, want)
+		}
+	})
+
+	t.Run("url forwarding", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+		defer server.Close()
+		client := newTestClient(t, server)
+
+		_, err := client.AddUrlForward(ctx, "example.com", &porkbun.UrlForward{
+			Subdomain:   "blog",
+			Location:    "https://example.net",
+			Type:        "temporary",
+			IncludePath: "yes",
+			Wildcard:    "no",
+		})
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		res, err := client.GetUrlForwarding(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if len(res.Forwards) != 1 || res.Forwards[0].Location != "https://example.net" {
+			t.Fatalf("got %+v, want one forward", res.Forwards)
+		}
+
+		if _, err := client.DeleteUrlForward(ctx, "example.com", res.Forwards[0].Id); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if forwards := server.Backend.Forwards("example.com"); len(forwards) != 0 {
+			t.Errorf("got %d forwards, want 0", len(forwards))
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package porkbun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go/psl"
+)
+
+// DomainName is a domain name split around its registrable domain, which is
+// what Porkbun manages as a zone.
+type DomainName struct {
+	// The labels in front of the registrable domain, such as "www" or "a.b".
+	// Empty for the registrable domain itself.
+	Subdomain string `json:"subdomain"`
+
+	// The registrable domain, such as "example.co.uk": the public suffix and
+	// one more label.
+	Domain string `json:"domain"`
+
+	// The public suffix, such as "co.uk".
+	Suffix string `json:"suffix"`
+}
+
+// String returns the full name.
+func (d DomainName) String() string {
+	if d.Subdomain == "" {
+		return d.Domain
+	}
+
+	return d.Subdomain + "." + d.Domain
+}
+
+// ParseDomain splits a domain name using the Public Suffix List from
+// psl.Default, so the registrable domain of foo.example.co.uk is found to be
+// example.co.uk, not co.uk. The name is converted with ToASCII first, and a
+// trailing dot is ignored.
+//
+//	"example.com"         -> "", "example.com", "com"
+//	"foo.bar.example.com" -> "foo.bar", "example.com", "com"
+//	"*.example.co.uk"     -> "*", "example.co.uk", "co.uk"
+//	"www.bücher.de"       -> "www", "xn--bcher-kva.de", "de"
+//
+// A name that is a public suffix itself has no registrable domain, and is an
+// error.
+func ParseDomain(name string) (*DomainName, error) {
+	ascii, err := ToASCII(strings.TrimSuffix(name, "."))
+	if err != nil {
+		return nil, err
+	}
+	ascii = strings.ToLower(ascii)
+
+	labels := strings.Split(ascii, ".")
+	for _, label := range labels {
+		if label == "" {
+			return nil, fmt.Errorf("invalid domain %q, it has an empty label", name)
+		}
+	}
+
+	suffix := psl.Default().PublicSuffix(ascii)
+	suffixLabels := strings.Count(suffix, ".") + 1
+	if len(labels) <= suffixLabels {
+		return nil, fmt.Errorf("invalid domain %q, it is a public suffix", name)
+	}
+
+	split := len(labels) - suffixLabels - 1
+	return &DomainName{
+		Subdomain: strings.Join(labels[:split], "."),
+		Domain:    strings.Join(labels[split:], "."),
+		Suffix:    suffix,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package porkbun_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+func TestParseDomain(t *testing.T) {
+	testCases := []struct {
+		name string
+		want *porkbun.DomainName
+	}{
+		{name: "example.com", want: &porkbun.DomainName{Domain: "example.com", Suffix: "com"}},
+		{name: "foo.example.com", want: &porkbun.DomainName{Subdomain: "foo", Domain: "example.com", Suffix: "com"}},
+		{name: "*.example.com", want: &porkbun.DomainName{Subdomain: "*", Domain: "example.com", Suffix: "com"}},
+		{name: "foo.bar.example.com.", want: &porkbun.DomainName{Subdomain: "foo.bar", Domain: "example.com", Suffix: "com"}},
+		{name: "foo.example.co.uk", want: &porkbun.DomainName{Subdomain: "foo", Domain: "example.co.uk", Suffix: "co.uk"}},
+		{name: "_dmarc.Example.CO.UK", want: &porkbun.DomainName{Subdomain: "_dmarc", Domain: "example.co.uk", Suffix: "co.uk"}},
+		{name: "shop.example.us.com", want: &porkbun.DomainName{Subdomain: "shop", Domain: "example.us.com", Suffix: "us.com"}},
+		{name: "www.bücher.de", want: &porkbun.DomainName{Subdomain: "www", Domain: "xn--bcher-kva.de", Suffix: "de"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := porkbun.ParseDomain(tc.name)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("string", func(t *testing.T) {
+		got, _ := porkbun.ParseDomain("foo.example.co.uk")
+		if got.String() != "foo.example.co.uk" {
+			t.Errorf("got %q, want %q", got, "foo.example.co.uk")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, name := range []string{"com", "co.uk", "", "foo..example.com", "-bücher.de"} {
+			if got, err := porkbun.ParseDomain(name); err == nil {
+				t.Errorf("got %+v, want an error for %q", got, name)
+			}
+		}
+	})
+}
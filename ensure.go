@@ -0,0 +1,92 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+)
+
+// The minimum and default TTL of a record.
+const defaultTTL TTL = 600
+
+type EnsureAction string
+
+const (
+	EnsureUnchanged EnsureAction = "unchanged"
+	EnsureCreated   EnsureAction = "created"
+	EnsureModified  EnsureAction = "modified"
+)
+
+type EnsureResult struct {
+	// What was done to make the record match.
+	Action EnsureAction `json:"action"`
+
+	// The Id of the record that now matches.
+	Id RecordID `json:"id"`
+}
+
+// EnsureDnsRecord makes sure a record exists with the given content, TTL and
+// priority, creating or modifying it as needed. It is safe to call repeatedly.
+//
+// Existing records are looked up by record.Name and record.Type. A record with
+// the same content is updated in place if its TTL or priority differ. If there
+// is none, but there is exactly one record of that name and type, it is
+// modified to the new content. If there are several, none of which hold the
+// content, it is not clear which to change, and an error is returned. TXT
+// content is compared by value, however it is split into strings.
+func (c *Client) EnsureDnsRecord(ctx context.Context, domain string, record *Record) (*EnsureResult, error) {
+	if record.Type == "" {
+		return nil, fmt.Errorf("record.Type must be set to ensure this entry")
+	}
+
+	existing, err := c.ListDnsRecords(ctx, domain, record.Name, record.Type)
+	if err != nil {
+		return nil, fmt.Errorf("err looking up dns record %q %q, %w", record.Name, record.Type, err)
+	}
+
+	var match *Record
+	for i := range existing.Records {
+		if sameContent(record.Type, existing.Records[i].Content, record.Content) {
+			match = &existing.Records[i]
+			break
+		}
+	}
+
+	if match == nil {
+		switch len(existing.Records) {
+		case 0:
+			res, err := c.CreateDnsRecord(ctx, domain, record)
+			if err != nil {
+				return nil, err
+			}
+
+			return &EnsureResult{Action: EnsureCreated, Id: res.Id}, nil
+		case 1:
+			match = &existing.Records[0]
+		default:
+			return nil, fmt.Errorf(
+				"found %d %q records for %q, and none with content %q; not sure which to modify",
+				len(existing.Records),
+				record.Type,
+				record.Name,
+				record.Content,
+			)
+		}
+	} else if sameTTL(match.TTL, record.TTL) && match.Priority == record.Priority {
+		return &EnsureResult{Action: EnsureUnchanged, Id: match.Id}, nil
+	}
+
+	desired := *record
+	desired.Id = match.Id
+
+	if _, err := c.ModifyDnsRecord(ctx, domain, &desired); err != nil {
+		return nil, err
+	}
+
+	return &EnsureResult{Action: EnsureModified, Id: match.Id}, nil
+}
+
+// sameTTL compares TTLs the way Porkbun stores them: zero means the default,
+// and anything lower is raised to it.
+func sameTTL(a, b TTL) bool {
+	return max(a, defaultTTL) == max(b, defaultTTL)
+}
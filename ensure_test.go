@@ -0,0 +1,115 @@
+package porkbun_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestEnsureDnsRecord(t *testing.T) {
+	ctx := context.TODO()
+
+	testCases := []struct {
+		msg      string
+		existing []porkbun.Record
+		record   porkbun.Record
+		want     porkbun.EnsureAction
+		content  []string
+	}{
+		{
+			msg:     "missing",
+			record:  porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"},
+			want:    porkbun.EnsureCreated,
+			content: []string{"10.0.0.1"},
+		},
+		{
+			msg:      "matching",
+			existing: []porkbun.Record{{Name: "www", Type: "A", Content: "10.0.0.1"}},
+			record:   porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1", TTL: 600, Priority: 0},
+			want:     porkbun.EnsureUnchanged,
+			content:  []string{"10.0.0.1"},
+		},
+		{
+			msg:      "default ttl and priority",
+			existing: []porkbun.Record{{Name: "www", Type: "A", Content: "10.0.0.1"}},
+			record:   porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"},
+			want:     porkbun.EnsureUnchanged,
+			content:  []string{"10.0.0.1"},
+		},
+		{
+			msg:      "different ttl",
+			existing: []porkbun.Record{{Name: "www", Type: "A", Content: "10.0.0.1"}},
+			record:   porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1", TTL: 3600},
+			want:     porkbun.EnsureModified,
+			content:  []string{"10.0.0.1"},
+		},
+		{
+			msg:      "different content",
+			existing: []porkbun.Record{{Name: "www", Type: "A", Content: "10.0.0.1"}},
+			record:   porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2"},
+			want:     porkbun.EnsureModified,
+			content:  []string{"10.0.0.2"},
+		},
+		{
+			msg: "one of several",
+			existing: []porkbun.Record{
+				{Name: "www", Type: "A", Content: "10.0.0.1"},
+				{Name: "www", Type: "A", Content: "10.0.0.2"},
+			},
+			record:  porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2", TTL: 1200},
+			want:    porkbun.EnsureModified,
+			content: []string{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+			defer server.Close()
+			for _, record := range tc.existing {
+				server.Backend.AddRecord("example.com", record)
+			}
+
+			res, err := newTestClient(t, server).EnsureDnsRecord(ctx, "example.com", &tc.record)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if res.Action != tc.want {
+				t.Errorf("got %s, want %s", res.Action, tc.want)
+			}
+
+			records := server.Backend.Records("example.com")
+			if len(records) != len(tc.content) {
+				t.Fatalf("got %d records, want %d", len(records), len(tc.content))
+			}
+
+			for i, record := range records {
+				if record.Content != tc.content[i] {
+					t.Errorf("got %s, want %s", record.Content, tc.content[i])
+				}
+
+				if record.Content == tc.record.Content && record.Id != res.Id {
+					t.Errorf("got id %s, want %s", res.Id, record.Id)
+				}
+			}
+		})
+	}
+
+	t.Run("ambiguous", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2"})
+
+		_, err := newTestClient(t, server).EnsureDnsRecord(ctx, "example.com", &porkbun.Record{
+			Name:    "www",
+			Type:    "A",
+			Content: "10.0.0.3",
+		})
+		if err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
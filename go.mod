@@ -2,8 +2,15 @@ module github.com/andrew-womeldorf/porkbun-go
 
 go 1.22.0
 
+require (
+	github.com/spf13/cobra v1.8.0
+	golang.org/x/net v0.35.0
+	sigs.k8s.io/yaml v1.6.0
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.8.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	go.yaml.in/yaml/v2 v2.4.2 // indirect
+	golang.org/x/text v0.22.0 // indirect
 )
@@ -0,0 +1,114 @@
+package porkbun
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// idnaProfile converts names by IDNA 2008, as registries do, but allows the
+// underscores of names such as _dmarc, and the * of wildcards.
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.ValidateLabels(true),
+	idna.StrictDomainName(false),
+	idna.Transitional(false),
+)
+
+// The prefix of a label encoded with punycode.
+const aceLabelPrefix = "xn--"
+
+// ToASCII converts a domain name to the form Porkbun expects, with every
+// Unicode label (U-label) encoded as punycode (A-label), as in bücher.example
+// to xn--bcher-kva.example. The name is checked against the IDNA rules, and
+// mapped as browsers do: lowercased, normalized, and with full width dots
+// turned into dots.
+//
+// Names that are already ASCII are returned as they are, so record names such
+// as _dmarc or * pass through. Their xn-- labels are checked to decode.
+func ToASCII(name string) (string, error) {
+	if !isASCII(name) {
+		ascii, err := idnaProfile.ToASCII(name)
+		if err != nil {
+			return "", fmt.Errorf("invalid domain name %q, %w", name, err)
+		}
+		return ascii, nil
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if !strings.HasPrefix(strings.ToLower(label), aceLabelPrefix) {
+			continue
+		}
+		if _, err := idnaProfile.ToUnicode(label); err != nil {
+			return "", fmt.Errorf("invalid domain name %q, %w", name, err)
+		}
+	}
+
+	return name, nil
+}
+
+// ToUnicode converts a domain name for display, with every punycode label
+// decoded, as in xn--bcher-kva.example to bücher.example. A name that can't
+// be decoded is returned as it is.
+func ToUnicode(name string) string {
+	if !strings.Contains(strings.ToLower(name), aceLabelPrefix) {
+		return name
+	}
+
+	unicode, err := idnaProfile.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+
+	return unicode
+}
+
+// UnicodeName returns the name of the record for display. See ToUnicode.
+func (r Record) UnicodeName() string {
+	return ToUnicode(r.Name)
+}
+
+// Record types whose content is a host name.
+var hostContentTypes = map[string]bool{
+	"ALIAS": true,
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+}
+
+// recordToASCII converts the name of a record, and the host names in its
+// content, with ToASCII.
+func recordToASCII(record *Record) error {
+	var err error
+	if record.Name, err = ToASCII(record.Name); err != nil {
+		return err
+	}
+
+	recordType := strings.ToUpper(record.Type)
+	switch {
+	case hostContentTypes[recordType]:
+		record.Content, err = ToASCII(record.Content)
+	case recordType == "SRV":
+		// weight port target
+		if fields := strings.Fields(record.Content); len(fields) == 3 {
+			if fields[2], err = ToASCII(fields[2]); err == nil {
+				record.Content = strings.Join(fields, " ")
+			}
+		}
+	}
+
+	return err
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,125 @@
+package porkbun_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestToASCII(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		name    string
+		ascii   string
+		unicode string
+		invalid bool
+	}{
+		{msg: "ascii", name: "www.example.com", ascii: "www.example.com", unicode: "www.example.com"},
+		{msg: "ascii keeps case", name: "WWW.example.com", ascii: "WWW.example.com", unicode: "WWW.example.com"},
+		{msg: "empty", name: "", ascii: "", unicode: ""},
+		{msg: "underscores", name: "_dmarc.bücher.de", ascii: "_dmarc.xn--bcher-kva.de", unicode: "_dmarc.bücher.de"},
+		{msg: "wildcard", name: "*.münchen.de", ascii: "*.xn--mnchen-3ya.de", unicode: "*.münchen.de"},
+		{msg: "mapped", name: "Bücher.example.", ascii: "xn--bcher-kva.example.", unicode: "bücher.example."},
+		{msg: "full width dots", name: "例え。テスト", ascii: "xn--r8jz45g.xn--zckzah", unicode: "例え.テスト"},
+		{msg: "not transitional", name: "faß.de", ascii: "xn--fa-hia.de", unicode: "faß.de"},
+		{msg: "a-label", name: "xn--bcher-kva.de", ascii: "xn--bcher-kva.de", unicode: "bücher.de"},
+		{msg: "bad a-label", name: "xn--zz.de", invalid: true},
+		{msg: "leading hyphen", name: "-bücher.de", invalid: true},
+		{msg: "mixed directions", name: "aא.example", invalid: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			ascii, err := porkbun.ToASCII(tc.name)
+			if tc.invalid {
+				if err == nil {
+					t.Errorf("got %q, want an error", ascii)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if ascii != tc.ascii {
+				t.Errorf("got %q, want %q", ascii, tc.ascii)
+			}
+			if unicode := porkbun.ToUnicode(ascii); unicode != tc.unicode {
+				t.Errorf("got %q, want %q", unicode, tc.unicode)
+			}
+		})
+	}
+
+	t.Run("undecodable unicode", func(t *testing.T) {
+		if got := porkbun.ToUnicode("xn--zz.de"); got != "xn--zz.de" {
+			t.Errorf("got %q, want it unchanged", got)
+		}
+	})
+}
+
+func TestValidateIDN(t *testing.T) {
+	valid := porkbun.Record{Name: "www.bücher", Type: "CNAME", Content: "shop.bücher.de"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("got %s, want nil", err)
+	}
+
+	for _, record := range []porkbun.Record{
+		{Name: "-www.bücher", Type: "A", Content: "192.0.2.1"},
+		{Name: "www", Type: "CNAME", Content: "xn--zz.de"},
+	} {
+		var validationErr *porkbun.ValidationError
+		if err := record.Validate(); !errors.As(err, &validationErr) {
+			t.Errorf("got %v, want a *ValidationError for %+v", err, record)
+		}
+	}
+}
+
+func TestClientIDN(t *testing.T) {
+	ctx := context.TODO()
+
+	server := porkbuntest.NewServer(porkbuntest.WithDomains("xn--bcher-kva.de"))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	record := &porkbun.Record{Name: "www", Type: "CNAME", Content: "shop.bücher.de"}
+	if _, err := client.CreateDnsRecord(ctx, "bücher.de", record); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if _, err := client.CreateDnsRecord(ctx, "bücher.de", &porkbun.Record{Name: "straße", Type: "A", Content: "192.0.2.1"}); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	res, err := client.ListDnsRecords(ctx, "bücher.de", "straße", "A")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if len(res.Records) != 1 {
+		t.Fatalf("got %d records, want 1", len(res.Records))
+	}
+	if got, want := res.Records[0].Name, "xn--strae-oqa.xn--bcher-kva.de"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := res.Records[0].UnicodeName(), "straße.bücher.de"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	res, err = client.ListDnsRecords(ctx, "xn--bcher-kva.de", "www", "CNAME")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if len(res.Records) != 1 || res.Records[0].Content != "shop.xn--bcher-kva.de" {
+		t.Errorf("got %+v, want the content in punycode", res.Records)
+	}
+
+	ensured, err := client.EnsureDnsRecord(ctx, "bücher.de", record)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if ensured.Action != porkbun.EnsureUnchanged {
+		t.Errorf("got %s, want %s", ensured.Action, porkbun.EnsureUnchanged)
+	}
+}
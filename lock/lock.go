@@ -0,0 +1,377 @@
+// Package lock implements a lease-based mutex on top of a TXT record, so
+// several machines editing the same zones can take turns.
+//
+// Each contender creates its own TXT record, such as _lock.example.com, and
+// the live record with the lowest id holds the lock. Porkbun hands out record
+// ids in increasing order, so the id of the winning record doubles as a
+// fencing token: a later holder always has a larger token than an earlier
+// one.
+//
+// Leases expire, so a holder that dies does not block everyone else forever.
+// Expiry is judged by the clocks of the contenders, which should be roughly
+// in sync.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+const (
+	// DefaultName is the subdomain of the lock record.
+	DefaultName = "_lock"
+
+	// DefaultLeaseDuration is how long a lease lasts without being renewed.
+	DefaultLeaseDuration = 5 * time.Minute
+
+	version = "lock1"
+)
+
+// ErrLocked is matched by a *HeldError with errors.Is.
+var ErrLocked = errors.New("lock is held")
+
+// ErrLeaseLost is returned when a lease expired and was taken by someone
+// else.
+var ErrLeaseLost = errors.New("lease lost")
+
+// HeldError is returned when someone else holds the lock.
+type HeldError struct {
+	Owner   string
+	Expires time.Time
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("%s by %q until %s", ErrLocked, e.Owner, e.Expires.Format(time.RFC3339))
+}
+
+func (e *HeldError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+type Option func(*Lock)
+
+// WithName sets the subdomain of the lock record. Use different names for
+// independent locks on the same domain.
+func WithName(name string) Option {
+	return func(l *Lock) {
+		l.name = name
+	}
+}
+
+// WithOwner identifies the holder in the lock record. It defaults to the
+// hostname and process id.
+func WithOwner(owner string) Option {
+	return func(l *Lock) {
+		l.owner = owner
+	}
+}
+
+// WithLeaseDuration sets how long a lease lasts without being renewed.
+func WithLeaseDuration(d time.Duration) Option {
+	return func(l *Lock) {
+		l.duration = d
+	}
+}
+
+// WithClock replaces time.Now, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(l *Lock) {
+		l.now = now
+	}
+}
+
+type Lock struct {
+	dns      porkbun.DNSService
+	domain   string
+	name     string
+	owner    string
+	duration time.Duration
+	now      func() time.Time
+}
+
+// New creates a lock stored in a TXT record on domain.
+func New(dns porkbun.DNSService, domain string, options ...Option) *Lock {
+	hostname, _ := os.Hostname()
+
+	l := &Lock{
+		dns:      dns,
+		domain:   domain,
+		name:     DefaultName,
+		owner:    fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+		duration: DefaultLeaseDuration,
+		now:      time.Now,
+	}
+
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+// Lease is a held lock.
+type Lease struct {
+	lock  *Lock
+	id    porkbun.RecordID
+	nonce string
+
+	// Token increases with every new holder of the lock. Pass it along with
+	// writes guarded by the lock, so stale holders can be rejected.
+	Token int64
+
+	Owner   string
+	Expires time.Time
+}
+
+// entry is the content of a lock record.
+type entry struct {
+	id      porkbun.RecordID
+	owner   string
+	expires time.Time
+	token   int64
+	nonce   string
+}
+
+func (e entry) String() string {
+	parts := []string{
+		"v=" + version,
+		"owner=" + url.QueryEscape(e.owner),
+		"expires=" + strconv.FormatInt(e.expires.Unix(), 10),
+		"nonce=" + e.nonce,
+	}
+	if e.token != 0 {
+		parts = append(parts, "token="+strconv.FormatInt(e.token, 10))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// parseEntry reads a lock record, reporting false for other TXT records.
+func parseEntry(record porkbun.Record) (entry, bool) {
+	e := entry{id: record.Id}
+
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.Trim(record.Content, `"`), ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		fields[key] = value
+	}
+
+	if fields["v"] != version {
+		return e, false
+	}
+
+	owner, err := url.QueryUnescape(fields["owner"])
+	if err != nil {
+		return e, false
+	}
+	e.owner = owner
+
+	expires, err := strconv.ParseInt(fields["expires"], 10, 64)
+	if err != nil {
+		return e, false
+	}
+	e.expires = time.Unix(expires, 0)
+
+	e.nonce = fields["nonce"]
+	e.token, _ = strconv.ParseInt(fields["token"], 10, 64)
+
+	return e, true
+}
+
+// entries returns the lock records, lowest id first.
+func (l *Lock) entries(ctx context.Context) ([]entry, error) {
+	res, err := l.dns.ListDnsRecords(ctx, l.domain, l.name, "TXT")
+	if err != nil {
+		return nil, fmt.Errorf("err reading lock records, %w", err)
+	}
+
+	var entries []entry
+	for _, record := range res.Records {
+		if e, ok := parseEntry(record); ok {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return recordID(entries[i].id) < recordID(entries[j].id)
+	})
+
+	return entries, nil
+}
+
+func recordID(id porkbun.RecordID) int64 {
+	n, _ := strconv.ParseInt(id.String(), 10, 64)
+	return n
+}
+
+// Acquire takes the lock, or returns a *HeldError if someone else holds it.
+// Expired leases are stolen.
+func (l *Lock) Acquire(ctx context.Context) (*Lease, error) {
+	entries, err := l.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := l.now()
+	for _, e := range entries {
+		if e.expires.After(now) {
+			return nil, &HeldError{Owner: e.owner, Expires: e.expires}
+		}
+	}
+
+	// Everything left is expired. Another contender may be stealing them at
+	// the same time, so failures here don't matter.
+	for _, e := range entries {
+		l.dns.DeleteDnsRecordById(ctx, l.domain, e.id)
+	}
+
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	mine := entry{
+		owner:   l.owner,
+		expires: now.Add(l.duration),
+		nonce:   hex.EncodeToString(nonce),
+	}
+
+	res, err := l.dns.CreateDnsRecord(ctx, l.domain, &porkbun.Record{
+		Name:    l.name,
+		Type:    "TXT",
+		Content: mine.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("err creating lock record, %w", err)
+	}
+	mine.id = res.Id
+
+	// Everyone who created a record at the same time agrees that the lowest
+	// live id wins.
+	entries, err = l.entries(ctx)
+	if err != nil {
+		l.dns.DeleteDnsRecordById(ctx, l.domain, mine.id)
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.id == mine.id {
+			break
+		}
+
+		if e.expires.After(now) {
+			l.dns.DeleteDnsRecordById(ctx, l.domain, mine.id)
+			return nil, &HeldError{Owner: e.owner, Expires: e.expires}
+		}
+	}
+
+	lease := &Lease{
+		lock:    l,
+		id:      mine.id,
+		nonce:   mine.nonce,
+		Token:   recordID(mine.id),
+		Owner:   mine.owner,
+		Expires: mine.expires,
+	}
+
+	// Record the token, so others can see it.
+	if err := lease.Renew(ctx); err != nil {
+		l.dns.DeleteDnsRecordById(ctx, l.domain, mine.id)
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// Wait retries Acquire every poll interval until the lock is taken or ctx is
+// done.
+func (l *Lock) Wait(ctx context.Context, poll time.Duration) (*Lease, error) {
+	for {
+		lease, err := l.Acquire(ctx)
+		if !errors.Is(err, ErrLocked) {
+			return lease, err
+		}
+
+		select {
+		case <-time.After(poll):
+		case <-ctx.Done():
+			return nil, errors.Join(ctx.Err(), err)
+		}
+	}
+}
+
+// current reads the lease's record, returning ErrLeaseLost if it is gone.
+func (le *Lease) current(ctx context.Context) (entry, error) {
+	l := le.lock
+
+	res, err := l.dns.GetDnsRecordById(ctx, l.domain, le.id)
+	if err != nil {
+		return entry{}, fmt.Errorf("err reading lock record, %w", err)
+	}
+
+	for _, record := range res.Records {
+		if e, ok := parseEntry(record); ok && record.Id == le.id && e.nonce == le.nonce {
+			return e, nil
+		}
+	}
+
+	return entry{}, ErrLeaseLost
+}
+
+// Renew extends the lease by the lease duration. It returns ErrLeaseLost if
+// the lease expired and someone else took the lock.
+func (le *Lease) Renew(ctx context.Context) error {
+	l := le.lock
+
+	if _, err := le.current(ctx); err != nil {
+		return err
+	}
+
+	e := entry{
+		owner:   le.Owner,
+		expires: l.now().Add(l.duration),
+		token:   le.Token,
+		nonce:   le.nonce,
+	}
+
+	_, err := l.dns.ModifyDnsRecord(ctx, l.domain, &porkbun.Record{
+		Id:      le.id,
+		Name:    l.name,
+		Type:    "TXT",
+		Content: e.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("err renewing lease, %w", err)
+	}
+
+	le.Expires = e.expires
+
+	return nil
+}
+
+// Release gives up the lock. It returns ErrLeaseLost if the lease had already
+// expired and been taken by someone else.
+func (le *Lease) Release(ctx context.Context) error {
+	l := le.lock
+
+	if _, err := le.current(ctx); err != nil {
+		return err
+	}
+
+	if _, err := l.dns.DeleteDnsRecordById(ctx, l.domain, le.id); err != nil {
+		return fmt.Errorf("err deleting lock record, %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/lock"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+// clock is a fake time.Now that tests move forward by hand.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.TODO()
+
+	setup := func() (*porkbuntest.DNS, *clock) {
+		return porkbuntest.NewDNS(porkbuntest.WithDomains("example.com")), &clock{now: time.Unix(1700000000, 0)}
+	}
+
+	newLock := func(dns porkbun.DNSService, c *clock, owner string) *lock.Lock {
+		return lock.New(
+			dns,
+			"example.com",
+			lock.WithOwner(owner),
+			lock.WithLeaseDuration(time.Minute),
+			lock.WithClock(c.Now),
+		)
+	}
+
+	t.Run("exclusive", func(t *testing.T) {
+		dns, c := setup()
+
+		lease, err := newLock(dns, c, "a").Acquire(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if lease.Owner != "a" || lease.Token == 0 {
+			t.Errorf("got %+v, want owner and token", lease)
+		}
+
+		_, err = newLock(dns, c, "b").Acquire(ctx)
+
+		var held *lock.HeldError
+		if !errors.As(err, &held) || !errors.Is(err, lock.ErrLocked) {
+			t.Fatalf("got %v, want HeldError", err)
+		}
+
+		if held.Owner != "a" {
+			t.Errorf("got %s, want %s", held.Owner, "a")
+		}
+
+		if err := lease.Release(ctx); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		next, err := newLock(dns, c, "b").Acquire(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if next.Token <= lease.Token {
+			t.Errorf("got token %d, want more than %d", next.Token, lease.Token)
+		}
+
+		if records := dns.Backend.Records("example.com"); len(records) != 1 {
+			t.Errorf("got %d records, want only the lock", len(records))
+		}
+	})
+
+	t.Run("record", func(t *testing.T) {
+		dns, c := setup()
+
+		lease, err := newLock(dns, c, "ci runner 1").Acquire(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		records := dns.Backend.Records("example.com")
+		if len(records) != 1 || records[0].Name != "_lock.example.com" || records[0].Type != "TXT" {
+			t.Fatalf("got %+v, want a TXT record", records)
+		}
+
+		for _, want := range []string{"owner=ci+runner+1", "expires=1700000060", "token=" + records[0].Id.String()} {
+			if !strings.Contains(records[0].Content, want) {
+				t.Errorf("got %s, want it to contain %s", records[0].Content, want)
+			}
+		}
+
+		if strconv.FormatInt(lease.Token, 10) != records[0].Id.String() {
+			t.Errorf("got token %d, want record id %s", lease.Token, records[0].Id)
+		}
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		dns, c := setup()
+
+		lease, err := newLock(dns, c, "a").Acquire(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		c.Advance(50 * time.Second)
+		if err := lease.Renew(ctx); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		c.Advance(50 * time.Second)
+		if _, err := newLock(dns, c, "b").Acquire(ctx); !errors.Is(err, lock.ErrLocked) {
+			t.Errorf("got %v, want %v", err, lock.ErrLocked)
+		}
+	})
+
+	t.Run("token not recorded", func(t *testing.T) {
+		server := porkbuntest.NewServer(
+			porkbuntest.WithDomains("example.com"),
+			porkbuntest.WithFaults(porkbuntest.Rule{
+				Endpoint: "dns/edit",
+				Fault:    porkbuntest.ServerError(http.StatusServiceUnavailable),
+			}),
+		)
+		defer server.Close()
+
+		client, err := porkbun.NewClient(
+			porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+			porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+			porkbun.WithBaseUrl(server.URL),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, c := setup()
+		if _, err := newLock(client, c, "a").Acquire(ctx); err == nil {
+			t.Fatalf("expected error")
+		}
+
+		if records := server.Backend.Records("example.com"); len(records) != 0 {
+			t.Errorf("got %+v, want the lock record deleted", records)
+		}
+	})
+
+	t.Run("steal expired", func(t *testing.T) {
+		dns, c := setup()
+
+		stale, err := newLock(dns, c, "a").Acquire(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		c.Advance(2 * time.Minute)
+
+		lease, err := newLock(dns, c, "b").Acquire(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if lease.Token <= stale.Token {
+			t.Errorf("got token %d, want more than %d", lease.Token, stale.Token)
+		}
+
+		if err := stale.Renew(ctx); !errors.Is(err, lock.ErrLeaseLost) {
+			t.Errorf("got %v, want %v", err, lock.ErrLeaseLost)
+		}
+
+		if err := stale.Release(ctx); !errors.Is(err, lock.ErrLeaseLost) {
+			t.Errorf("got %v, want %v", err, lock.ErrLeaseLost)
+		}
+	})
+
+	t.Run("contention", func(t *testing.T) {
+		dns, c := setup()
+
+		var wg sync.WaitGroup
+		leases := make(chan *lock.Lease, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if lease, err := newLock(dns, c, string(rune('a'+i))).Acquire(ctx); err == nil {
+					leases <- lease
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(leases)
+
+		if len(leases) != 1 {
+			t.Errorf("got %d holders, want 1", len(leases))
+		}
+
+		if records := dns.Backend.Records("example.com"); len(records) != 1 {
+			t.Errorf("got %d records, want losers to clean up", len(records))
+		}
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		dns, c := setup()
+
+		lease, err := newLock(dns, c, "a").Acquire(ctx)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			lease.Release(ctx)
+		}()
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		if _, err := newLock(dns, c, "b").Wait(ctx, 5*time.Millisecond); err != nil {
+			t.Errorf("got %s, want nil", err)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package mailpolicy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// The name under which DMARC records are published.
+const dmarcPrefix = "_dmarc"
+
+// What a receiver should do with mail that fails DMARC.
+type Disposition string
+
+const (
+	None       Disposition = "none"
+	Quarantine Disposition = "quarantine"
+	Reject     Disposition = "reject"
+)
+
+// Alignment is how closely the SPF or DKIM domain must match the From domain.
+type Alignment string
+
+const (
+	Relaxed Alignment = "r"
+	Strict  Alignment = "s"
+)
+
+// DMARC is a DMARC record. Empty fields are left out, so receivers use their
+// defaults.
+type DMARC struct {
+	// The p= tag, required.
+	Policy Disposition
+
+	// The sp= tag, for mail from subdomains.
+	SubdomainPolicy Disposition
+
+	// The pct= tag, from 0 to 100. Nil means 100.
+	Percent *int
+
+	// The rua= and ruf= tags: mailto: or https: addresses to send aggregate
+	// and failure reports to.
+	AggregateReports []string
+	FailureReports   []string
+
+	// The adkim= and aspf= tags.
+	DKIMAlignment Alignment
+	SPFAlignment  Alignment
+
+	// Tags with no field of their own, such as fo= and ri=, kept in order.
+	Other []Tag
+}
+
+// DMARCName returns the name of the DMARC record for the subdomain name;
+// empty is the apex.
+func DMARCName(name string) string {
+	return recordName(dmarcPrefix, name)
+}
+
+// ParseDMARC reads the content of a DMARC TXT record.
+func ParseDMARC(content string) (*DMARC, error) {
+	tags, err := parseTags(content, "DMARC1")
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DMARC{}
+	for _, tag := range tags {
+		switch tag.Name {
+		case "p":
+			d.Policy = Disposition(tag.Value)
+		case "sp":
+			d.SubdomainPolicy = Disposition(tag.Value)
+		case "pct":
+			percent, err := strconv.Atoi(tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("pct %q is not a number", tag.Value)
+			}
+			d.Percent = &percent
+		case "rua":
+			if d.AggregateReports, err = splitURIs(tag.Value); err != nil {
+				return nil, err
+			}
+		case "ruf":
+			if d.FailureReports, err = splitURIs(tag.Value); err != nil {
+				return nil, err
+			}
+		case "adkim":
+			d.DKIMAlignment = Alignment(tag.Value)
+		case "aspf":
+			d.SPFAlignment = Alignment(tag.Value)
+		default:
+			d.Other = append(d.Other, tag)
+		}
+	}
+
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Validate checks the values of the tags.
+func (d *DMARC) Validate() error {
+	if d.Policy == "" {
+		return fmt.Errorf("dmarc record has no p= tag")
+	}
+	if !validDisposition(d.Policy) {
+		return fmt.Errorf("p=%s is not none, quarantine or reject", d.Policy)
+	}
+	if d.SubdomainPolicy != "" && !validDisposition(d.SubdomainPolicy) {
+		return fmt.Errorf("sp=%s is not none, quarantine or reject", d.SubdomainPolicy)
+	}
+	if d.Percent != nil && (*d.Percent < 0 || *d.Percent > 100) {
+		return fmt.Errorf("pct=%d is not between 0 and 100", *d.Percent)
+	}
+	for _, uri := range append(append([]string{}, d.AggregateReports...), d.FailureReports...) {
+		if err := checkURI(uri); err != nil {
+			return err
+		}
+	}
+	if d.DKIMAlignment != "" && d.DKIMAlignment != Relaxed && d.DKIMAlignment != Strict {
+		return fmt.Errorf("adkim=%s is not r or s", d.DKIMAlignment)
+	}
+	if d.SPFAlignment != "" && d.SPFAlignment != Relaxed && d.SPFAlignment != Strict {
+		return fmt.Errorf("aspf=%s is not r or s", d.SPFAlignment)
+	}
+
+	return nil
+}
+
+func validDisposition(d Disposition) bool {
+	return d == None || d == Quarantine || d == Reject
+}
+
+func (d *DMARC) String() string {
+	tags := []Tag{{Name: "p", Value: string(d.Policy)}}
+	if d.SubdomainPolicy != "" {
+		tags = append(tags, Tag{Name: "sp", Value: string(d.SubdomainPolicy)})
+	}
+	if d.Percent != nil {
+		tags = append(tags, Tag{Name: "pct", Value: strconv.Itoa(*d.Percent)})
+	}
+	if len(d.AggregateReports) > 0 {
+		tags = append(tags, Tag{Name: "rua", Value: strings.Join(d.AggregateReports, ",")})
+	}
+	if len(d.FailureReports) > 0 {
+		tags = append(tags, Tag{Name: "ruf", Value: strings.Join(d.FailureReports, ",")})
+	}
+	if d.DKIMAlignment != "" {
+		tags = append(tags, Tag{Name: "adkim", Value: string(d.DKIMAlignment)})
+	}
+	if d.SPFAlignment != "" {
+		tags = append(tags, Tag{Name: "aspf", Value: string(d.SPFAlignment)})
+	}
+
+	return formatTags("DMARC1", append(tags, d.Other...))
+}
+
+// Record builds the TXT record publishing the policy for the subdomain name;
+// empty is the apex.
+func (d *DMARC) Record(name string) (porkbun.Record, error) {
+	if err := d.Validate(); err != nil {
+		return porkbun.Record{}, err
+	}
+
+	return porkbun.Record{Name: DMARCName(name), Type: "TXT", Content: d.String()}, nil
+}
@@ -0,0 +1,91 @@
+// Package mailpolicy builds and parses the records that tell other mail
+// servers how to treat mail for a domain: DMARC at _dmarc, MTA-STS at
+// _mta-sts, and TLS-RPT at _smtp._tls.
+//
+// MTA-STS is published in two halves. The policy itself is a file served over
+// HTTPS from mta-sts.DOMAIN, which Porkbun cannot host, and the TXT record
+// only carries an id. Senders cache the policy until the id changes, so the id
+// must change along with the policy. Policy.ID derives it from the policy, so
+// publishing the record for a policy always gives it a fresh id when needed.
+package mailpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// Tag is a name=value pair of a policy record.
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// parseTags reads the tags of a record whose first tag must be v=version.
+// Content split into quoted strings is joined first.
+func parseTags(content, version string) ([]Tag, error) {
+	var tags []Tag
+	for _, part := range strings.Split(porkbun.JoinText(content), ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("tag %q has no value", part)
+		}
+		tags = append(tags, Tag{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+
+	if len(tags) == 0 || tags[0].Name != "v" || tags[0].Value != version {
+		return nil, fmt.Errorf("record does not start with v=%s", version)
+	}
+
+	return tags[1:], nil
+}
+
+// formatTags writes tags after v=version.
+func formatTags(version string, tags []Tag) string {
+	parts := []string{"v=" + version}
+	for _, tag := range tags {
+		parts = append(parts, tag.Name+"="+tag.Value)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// splitURIs reads a comma separated list of reporting addresses.
+func splitURIs(value string) ([]string, error) {
+	var uris []string
+	for _, uri := range strings.Split(value, ",") {
+		uri = strings.TrimSpace(uri)
+		if err := checkURI(uri); err != nil {
+			return nil, err
+		}
+		uris = append(uris, uri)
+	}
+
+	return uris, nil
+}
+
+// checkURI makes sure a reporting address is a mailto: or https: URI.
+func checkURI(uri string) error {
+	scheme, rest, ok := strings.Cut(uri, ":")
+	if !ok || rest == "" || (scheme != "mailto" && scheme != "https") {
+		return fmt.Errorf("report address %q must be a mailto: or https: uri", uri)
+	}
+
+	return nil
+}
+
+// recordName returns the name of a record under the subdomain name; empty is
+// the apex.
+func recordName(prefix, name string) string {
+	if name == "" || name == "@" {
+		return prefix
+	}
+
+	return prefix + "." + name
+}
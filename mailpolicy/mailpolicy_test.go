@@ -0,0 +1,221 @@
+package mailpolicy_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go/mailpolicy"
+)
+
+func TestParseDMARC(t *testing.T) {
+	half := 50
+
+	testCases := []struct {
+		msg     string
+		content string
+		want    *mailpolicy.DMARC
+	}{
+		{
+			msg:     "minimal",
+			content: "v=DMARC1; p=none",
+			want:    &mailpolicy.DMARC{Policy: mailpolicy.None},
+		},
+		{
+			msg:     "every field",
+			content: "v=DMARC1; p=reject; sp=quarantine; pct=50; rua=mailto:a@example.com, https://r.example.com/dmarc; ruf=mailto:f@example.com; adkim=s; aspf=r; fo=1",
+			want: &mailpolicy.DMARC{
+				Policy:           mailpolicy.Reject,
+				SubdomainPolicy:  mailpolicy.Quarantine,
+				Percent:          &half,
+				AggregateReports: []string{"mailto:a@example.com", "https://r.example.com/dmarc"},
+				FailureReports:   []string{"mailto:f@example.com"},
+				DKIMAlignment:    mailpolicy.Strict,
+				SPFAlignment:     mailpolicy.Relaxed,
+				Other:            []mailpolicy.Tag{{Name: "fo", Value: "1"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			got, err := mailpolicy.ParseDMARC(tc.content)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+
+			again, err := mailpolicy.ParseDMARC(got.String())
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("got %+v %v from %q, want it to round trip", again, err, got)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, content := range []string{
+			"p=reject; v=DMARC1",
+			"v=DMARC1; sp=reject",
+			"v=DMARC1; p=block",
+			"v=DMARC1; p=none; pct=150",
+			"v=DMARC1; p=none; rua=dmarc@example.com",
+			"v=DMARC1; p=none; adkim=x",
+		} {
+			if _, err := mailpolicy.ParseDMARC(content); err == nil {
+				t.Errorf("got nil, want an error for %q", content)
+			}
+		}
+	})
+}
+
+func TestDMARCRecord(t *testing.T) {
+	d := &mailpolicy.DMARC{Policy: mailpolicy.Quarantine, AggregateReports: []string{"mailto:dmarc@example.com"}}
+
+	testCases := []struct {
+		name string
+		want string
+	}{
+		{name: "", want: "_dmarc"},
+		{name: "mail", want: "_dmarc.mail"},
+	}
+
+	for _, tc := range testCases {
+		record, err := d.Record(tc.name)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if record.Name != tc.want || record.Type != "TXT" {
+			t.Errorf("got %q %q, want %q TXT", record.Name, record.Type, tc.want)
+		}
+		if want := "v=DMARC1; p=quarantine; rua=mailto:dmarc@example.com"; record.Content != want {
+			t.Errorf("got %q, want %q", record.Content, want)
+		}
+	}
+
+	if _, err := (&mailpolicy.DMARC{}).Record(""); err == nil {
+		t.Error("got nil, want an error without a policy")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	want := &mailpolicy.Policy{
+		Mode:   mailpolicy.Enforce,
+		MX:     []string{"mx1.example.com", "*.mail.example.com"},
+		MaxAge: 7 * 24 * time.Hour,
+	}
+
+	got, err := mailpolicy.ParsePolicy("version: STSv1\nmode: enforce\nmx: mx1.example.com\nmx: *.mail.example.com\nmax_age: 604800\n")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	text := got.String()
+	if !strings.HasSuffix(text, "max_age: 604800\r\n") || strings.Count(text, "\r\n") != 5 {
+		t.Errorf("got %q, want CRLF lines", text)
+	}
+
+	// The mail servers come back sorted, which is the same policy.
+	again, err := mailpolicy.ParsePolicy(text)
+	if err != nil || again.String() != text {
+		t.Errorf("got %+v %v from %q, want it to round trip", again, err, text)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, content := range []string{
+			"mode: enforce\nmx: mx.example.com\nmax_age: 86400",
+			"version: STSv1\nmode: strict\nmx: mx.example.com\nmax_age: 86400",
+			"version: STSv1\nmode: enforce\nmax_age: 86400",
+			"version: STSv1\nmode: testing\nmx: mx.*.example.com\nmax_age: 86400",
+			"version: STSv1\nmode: testing\nmx: mx.example.com\nmax_age: 99999999",
+		} {
+			if _, err := mailpolicy.ParsePolicy(content); err == nil {
+				t.Errorf("got nil, want an error for %q", content)
+			}
+		}
+	})
+}
+
+func TestPolicyID(t *testing.T) {
+	policy := &mailpolicy.Policy{Mode: mailpolicy.Testing, MX: []string{"mx.example.com"}, MaxAge: 24 * time.Hour}
+
+	record, err := policy.Record("")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if record.Name != "_mta-sts" {
+		t.Errorf("got %q, want %q", record.Name, "_mta-sts")
+	}
+
+	parsed, err := mailpolicy.ParseMTASTS(record.Content)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if parsed.ID != policy.ID() {
+		t.Errorf("got id %q, want %q", parsed.ID, policy.ID())
+	}
+
+	same := *policy
+	if same.ID() != policy.ID() {
+		t.Errorf("got %q, want the same id for the same policy", same.ID())
+	}
+
+	reordered := &mailpolicy.Policy{
+		Mode:   mailpolicy.Testing,
+		MX:     []string{"MX2.example.com.", "mx.example.com", "mx2.example.com"},
+		MaxAge: 24 * time.Hour,
+	}
+	sorted := &mailpolicy.Policy{
+		Mode:   mailpolicy.Testing,
+		MX:     []string{"mx.example.com", "mx2.example.com"},
+		MaxAge: 24 * time.Hour,
+	}
+	if reordered.ID() != sorted.ID() {
+		t.Errorf("got %q, want %q for the same mail servers in another order", reordered.ID(), sorted.ID())
+	}
+
+	changed := *policy
+	changed.Mode = mailpolicy.Enforce
+	if changed.ID() == policy.ID() {
+		t.Errorf("got %q, want the id to change with the policy", changed.ID())
+	}
+
+	for _, content := range []string{"v=STSv1;", "v=STSv1; id=20240101-1", "v=STSv2; id=1"} {
+		if _, err := mailpolicy.ParseMTASTS(content); err == nil {
+			t.Errorf("got nil, want an error for %q", content)
+		}
+	}
+}
+
+func TestParseTLSRPT(t *testing.T) {
+	got, err := mailpolicy.ParseTLSRPT(`"v=TLSRPTv1; rua=mailto:tls@example.com,https://r.example.com/tls"`)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	want := &mailpolicy.TLSRPT{Reports: []string{"mailto:tls@example.com", "https://r.example.com/tls"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	record, err := got.Record("mail")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if record.Name != "_smtp._tls.mail" || record.Content != "v=TLSRPTv1; rua=mailto:tls@example.com,https://r.example.com/tls" {
+		t.Errorf("got %q %q", record.Name, record.Content)
+	}
+
+	for _, content := range []string{"v=TLSRPTv1", "v=TLSRPTv1; rua=tls@example.com"} {
+		if _, err := mailpolicy.ParseTLSRPT(content); err == nil {
+			t.Errorf("got nil, want an error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package mailpolicy
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// The name under which MTA-STS records are published.
+const mtaSTSPrefix = "_mta-sts"
+
+// The longest max_age a policy may have, about a year.
+const MaxPolicyAge = 31557600 * time.Second
+
+// The length of the ids made by Policy.ID. The record allows up to 32.
+const idLength = 20
+
+// Mode says what senders should do when a TLS connection can't be verified.
+type Mode string
+
+const (
+	Enforce Mode = "enforce"
+	Testing Mode = "testing"
+	NoMode  Mode = "none"
+)
+
+// MTASTS is the MTA-STS TXT record, which tells senders which version of the
+// policy is current.
+type MTASTS struct {
+	// The id= tag, up to 32 letters and digits.
+	ID string
+
+	// Tags other than id=, kept in order.
+	Other []Tag
+}
+
+// MTASTSName returns the name of the MTA-STS record for the subdomain name;
+// empty is the apex.
+func MTASTSName(name string) string {
+	return recordName(mtaSTSPrefix, name)
+}
+
+// ParseMTASTS reads the content of an MTA-STS TXT record.
+func ParseMTASTS(content string) (*MTASTS, error) {
+	tags, err := parseTags(content, "STSv1")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MTASTS{}
+	for _, tag := range tags {
+		if tag.Name == "id" {
+			m.ID = tag.Value
+		} else {
+			m.Other = append(m.Other, tag)
+		}
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Validate checks the id.
+func (m *MTASTS) Validate() error {
+	if m.ID == "" || len(m.ID) > 32 {
+		return fmt.Errorf("id %q must be 1 to 32 characters", m.ID)
+	}
+	for _, c := range m.ID {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return fmt.Errorf("id %q must only hold letters and digits", m.ID)
+		}
+	}
+
+	return nil
+}
+
+func (m *MTASTS) String() string {
+	return formatTags("STSv1", append([]Tag{{Name: "id", Value: m.ID}}, m.Other...))
+}
+
+// Record builds the TXT record for the subdomain name; empty is the apex.
+func (m *MTASTS) Record(name string) (porkbun.Record, error) {
+	if err := m.Validate(); err != nil {
+		return porkbun.Record{}, err
+	}
+
+	return porkbun.Record{Name: MTASTSName(name), Type: "TXT", Content: m.String()}, nil
+}
+
+// Policy is an MTA-STS policy file, served from
+// https://mta-sts.DOMAIN/.well-known/mta-sts.txt.
+type Policy struct {
+	Mode Mode
+
+	// The mail servers senders may deliver to. A name may start with *. to
+	// match any single label, as in *.mail.example.com.
+	MX []string
+
+	// How long senders may cache the policy, in whole seconds.
+	MaxAge time.Duration
+}
+
+// ParsePolicy reads a policy file. Lines may end with CRLF or LF.
+func ParsePolicy(content string) (*Policy, error) {
+	p := &Policy{}
+	version := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %q has no value", line)
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "version":
+			version = value
+		case "mode":
+			p.Mode = Mode(value)
+		case "mx":
+			p.MX = append(p.MX, value)
+		case "max_age":
+			seconds, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("max_age %q is not a number of seconds", value)
+			}
+			p.MaxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if version != "STSv1" {
+		return nil, fmt.Errorf("unknown version %q", version)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Validate checks the mode, mail servers and max age.
+func (p *Policy) Validate() error {
+	if p.Mode != Enforce && p.Mode != Testing && p.Mode != NoMode {
+		return fmt.Errorf("mode %q is not enforce, testing or none", p.Mode)
+	}
+	if p.Mode != NoMode && len(p.MX) == 0 {
+		return fmt.Errorf("mode %s needs at least one mx", p.Mode)
+	}
+	for _, mx := range p.MX {
+		if mx == "" || strings.Contains(strings.TrimPrefix(mx, "*."), "*") {
+			return fmt.Errorf("mx %q is not a host name or *. pattern", mx)
+		}
+	}
+	if p.MaxAge <= 0 || p.MaxAge > MaxPolicyAge {
+		return fmt.Errorf("max age %s is not between 1s and %s", p.MaxAge, MaxPolicyAge)
+	}
+
+	return nil
+}
+
+// String writes the policy file, with CRLF line endings as RFC 8461 has it.
+// The mail servers are written in lower case, sorted and without duplicates,
+// so the same servers always make the same file.
+func (p *Policy) String() string {
+	var b strings.Builder
+	b.WriteString("version: STSv1\r\n")
+	b.WriteString("mode: " + string(p.Mode) + "\r\n")
+	for _, mx := range p.sortedMX() {
+		b.WriteString("mx: " + mx + "\r\n")
+	}
+	b.WriteString("max_age: " + strconv.Itoa(int(p.MaxAge/time.Second)) + "\r\n")
+
+	return b.String()
+}
+
+// ID derives a record id from the policy. Any change to the policy changes
+// the id, and the same policy always gets the same one, whatever order its
+// mail servers are in, so publishing it is safe to repeat.
+func (p *Policy) ID() string {
+	sum := sha256.Sum256([]byte(p.String()))
+	return hex.EncodeToString(sum[:])[:idLength]
+}
+
+// sortedMX returns the mail servers in lower case, without a trailing dot,
+// sorted and without duplicates.
+func (p *Policy) sortedMX() []string {
+	mx := make([]string, len(p.MX))
+	for i, host := range p.MX {
+		mx[i] = strings.ToLower(strings.TrimSuffix(host, "."))
+	}
+	slices.Sort(mx)
+
+	return slices.Compact(mx)
+}
+
+// Record builds the TXT record announcing the policy, for the subdomain name;
+// empty is the apex.
+func (p *Policy) Record(name string) (porkbun.Record, error) {
+	if err := p.Validate(); err != nil {
+		return porkbun.Record{}, err
+	}
+
+	m := &MTASTS{ID: p.ID()}
+	return m.Record(name)
+}
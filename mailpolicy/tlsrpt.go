@@ -0,0 +1,79 @@
+package mailpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// The name under which TLS-RPT records are published.
+const tlsRPTPrefix = "_smtp._tls"
+
+// TLSRPT is a TLS-RPT record, saying where to report failed TLS connections.
+type TLSRPT struct {
+	// The rua= tag: mailto: or https: addresses to send reports to.
+	Reports []string
+
+	// Tags other than rua=, kept in order.
+	Other []Tag
+}
+
+// TLSRPTName returns the name of the TLS-RPT record for the subdomain name;
+// empty is the apex.
+func TLSRPTName(name string) string {
+	return recordName(tlsRPTPrefix, name)
+}
+
+// ParseTLSRPT reads the content of a TLS-RPT TXT record.
+func ParseTLSRPT(content string) (*TLSRPT, error) {
+	tags, err := parseTags(content, "TLSRPTv1")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TLSRPT{}
+	for _, tag := range tags {
+		if tag.Name != "rua" {
+			t.Other = append(t.Other, tag)
+			continue
+		}
+
+		if t.Reports, err = splitURIs(tag.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Validate checks the report addresses.
+func (t *TLSRPT) Validate() error {
+	if len(t.Reports) == 0 {
+		return fmt.Errorf("tls-rpt record has no rua= addresses")
+	}
+	for _, uri := range t.Reports {
+		if err := checkURI(uri); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *TLSRPT) String() string {
+	return formatTags("TLSRPTv1", append([]Tag{{Name: "rua", Value: strings.Join(t.Reports, ",")}}, t.Other...))
+}
+
+// Record builds the TXT record for the subdomain name; empty is the apex.
+func (t *TLSRPT) Record(name string) (porkbun.Record, error) {
+	if err := t.Validate(); err != nil {
+		return porkbun.Record{}, err
+	}
+
+	return porkbun.Record{Name: TLSRPTName(name), Type: "TXT", Content: t.String()}, nil
+}
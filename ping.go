@@ -26,6 +26,10 @@ func (c *Client) Ping(ctx context.Context) (*PingResponse, error) {
 	}
 	defer res.Body.Close()
 
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
 	var response PingResponse
 	decoder := json.NewDecoder(res.Body)
 	if err := decoder.Decode(&response); err != nil {
@@ -0,0 +1,302 @@
+package porkbuntest
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// Porkbun silently raises any TTL below this value.
+const minTTL porkbun.TTL = 600
+
+var recordTypes = map[string]bool{
+	"A":     true,
+	"MX":    true,
+	"CNAME": true,
+	"ALIAS": true,
+	"TXT":   true,
+	"NS":    true,
+	"AAAA":  true,
+	"SRV":   true,
+	"TLSA":  true,
+	"CAA":   true,
+	"HTTPS": true,
+	"SVCB":  true,
+}
+
+type zone struct {
+	info        porkbun.Domain
+	records     []porkbun.Record
+	forwards    []porkbun.UrlForward
+	nameservers []string
+}
+
+// Backend is the in-memory state of a fake Porkbun account. It implements
+// http.Handler, serving the v3 JSON API. It is safe for concurrent use.
+type Backend struct {
+	mu        sync.Mutex
+	apiKey    string
+	secretKey string
+	ip        string
+	nextID    int
+	zones     map[string]*zone
+	rules     []*Rule
+	calls     map[string]int
+}
+
+// NewBackend creates an empty account that accepts the default credentials.
+func NewBackend(options ...Option) *Backend {
+	b := &Backend{
+		apiKey:    DefaultApiKey,
+		secretKey: DefaultSecretKey,
+		nextID:    100000000,
+		zones:     map[string]*zone{},
+		calls:     map[string]int{},
+	}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	return b
+}
+
+// AddDomain registers a domain on the account. Adding a domain that already
+// exists is a no-op.
+func (b *Backend) AddDomain(domain string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.addDomain(domain)
+}
+
+func (b *Backend) addDomain(domain string) *zone {
+	domain = strings.ToLower(domain)
+	if z, ok := b.zones[domain]; ok {
+		return z
+	}
+
+	now := time.Now().UTC()
+	tld := domain
+	if i := strings.Index(domain, "."); i >= 0 {
+		tld = domain[i+1:]
+	}
+
+	z := &zone{
+		info: porkbun.Domain{
+			Domain:       domain,
+			Status:       "ACTIVE",
+			TLD:          tld,
+			CreateDate:   now.Format(time.DateTime),
+			ExpireDate:   now.AddDate(1, 0, 0).Format(time.DateTime),
+			SecurityLock: "1",
+			WhoisPrivacy: "1",
+			AutoRenew:    1,
+		},
+		nameservers: []string{
+			"curitiba.ns.porkbun.com",
+			"fortaleza.ns.porkbun.com",
+			"maceio.ns.porkbun.com",
+			"salvador.ns.porkbun.com",
+		},
+	}
+	b.zones[domain] = z
+
+	return z
+}
+
+// Domains returns the names of every domain on the account, sorted.
+func (b *Backend) Domains() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.domainNames()
+}
+
+func (b *Backend) domainNames() []string {
+	names := make([]string, 0, len(b.zones))
+	for name := range b.zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// AddRecord creates a record the same way the create endpoint does, adding
+// the domain first if needed. record.Name is the subdomain. It returns the id
+// of the new record.
+func (b *Backend) AddRecord(domain string, record porkbun.Record) (porkbun.RecordID, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	z := b.addDomain(domain)
+	id, err := b.createRecord(z, record)
+	if err != nil {
+		return "", err
+	}
+
+	return porkbun.RecordID(strconv.Itoa(id)), nil
+}
+
+// Records returns a copy of the records on a domain, in creation order. Names
+// are fully qualified, as Porkbun returns them.
+func (b *Backend) Records(domain string) []porkbun.Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	z, ok := b.zones[strings.ToLower(domain)]
+	if !ok {
+		return nil
+	}
+
+	return append([]porkbun.Record(nil), z.records...)
+}
+
+// Forwards returns a copy of the URL forwards on a domain.
+func (b *Backend) Forwards(domain string) []porkbun.UrlForward {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	z, ok := b.zones[strings.ToLower(domain)]
+	if !ok {
+		return nil
+	}
+
+	return append([]porkbun.UrlForward(nil), z.forwards...)
+}
+
+func (b *Backend) id() int {
+	b.nextID++
+	return b.nextID
+}
+
+// fqdn joins a subdomain onto a domain, the way Porkbun names records.
+func fqdn(subdomain, domain string) string {
+	subdomain = strings.ToLower(strings.TrimSuffix(subdomain, "."))
+	if subdomain == "" || subdomain == "@" {
+		return domain
+	}
+
+	return subdomain + "." + domain
+}
+
+// normalize validates a record and fills in Porkbun's defaults. Name must
+// already be fully qualified.
+func normalize(record porkbun.Record) (porkbun.Record, error) {
+	record.Type = strings.ToUpper(record.Type)
+	if !recordTypes[record.Type] {
+		return record, badRequest("Invalid type passed.")
+	}
+
+	if record.Content == "" {
+		return record, badRequest("Content is required.")
+	}
+
+	record.TTL = max(record.TTL, minTTL)
+
+	return record, nil
+}
+
+// conflicts reports whether candidate may not live alongside the records
+// already in the zone. Records at the skip indexes are being replaced, and are
+// ignored.
+func (z *zone) conflicts(candidate porkbun.Record, skip ...int) bool {
+	for i, existing := range z.records {
+		if slices.Contains(skip, i) || existing.Name != candidate.Name {
+			continue
+		}
+
+		if existing.Type == candidate.Type && existing.Content == candidate.Content {
+			return true
+		}
+
+		if existing.Type == "CNAME" || candidate.Type == "CNAME" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Backend) createRecord(z *zone, record porkbun.Record) (int, error) {
+	record.Name = fqdn(record.Name, z.info.Domain)
+
+	record, err := normalize(record)
+	if err != nil {
+		return 0, badRequest("Create error: " + err.Error())
+	}
+
+	if z.conflicts(record) {
+		return 0, badRequest("Create error: We were unable to create the DNS record.")
+	}
+
+	id := b.id()
+	record.Id = porkbun.RecordID(strconv.Itoa(id))
+	z.records = append(z.records, record)
+
+	return id, nil
+}
+
+// match returns the indexes of records with the given fully qualified name and
+// type.
+func (z *zone) match(name, recordType string) []int {
+	var indexes []int
+	for i, record := range z.records {
+		if record.Name == name && record.Type == strings.ToUpper(recordType) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+func (z *zone) find(id porkbun.RecordID) int {
+	for i, record := range z.records {
+		if record.Id == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// replace overwrites the record at index i. Records at the siblings indexes
+// are being replaced in the same call, and may end up identical to it.
+func (z *zone) replace(i int, record porkbun.Record, siblings ...int) error {
+	record.Id = z.records[i].Id
+	record.Notes = z.records[i].Notes
+
+	record, err := normalize(record)
+	if err != nil {
+		return err
+	}
+
+	if z.conflicts(record, append(siblings, i)...) {
+		return badRequest("We were unable to edit the DNS record.")
+	}
+
+	z.records[i] = record
+
+	return nil
+}
+
+func (z *zone) remove(indexes ...int) {
+	drop := map[int]bool{}
+	for _, i := range indexes {
+		drop[i] = true
+	}
+
+	kept := z.records[:0]
+	for i, record := range z.records {
+		if !drop[i] {
+			kept = append(kept, record)
+		}
+	}
+	z.records = kept
+}
@@ -0,0 +1,143 @@
+package porkbuntest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// DNS implements porkbun.DNSService in memory, without HTTP. Records live in a
+// Backend and follow the same rules as the fake server, and failures are
+// returned as *porkbun.ApiError just like the client returns them. Injected
+// faults only apply to HTTP calls.
+type DNS struct {
+	Backend *Backend
+}
+
+var _ porkbun.DNSService = (*DNS)(nil)
+
+// NewDNS creates an in-memory DNS service on an empty account.
+func NewDNS(options ...Option) *DNS {
+	return &DNS{Backend: NewBackend(options...)}
+}
+
+// call runs an endpoint handler directly, and decodes its result into
+// response.
+func (d *DNS) call(ctx context.Context, endpoint string, params any, response any, args ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	req := &request{
+		path:   append(strings.Split(endpoint, "/"), args...),
+		fields: map[string]json.RawMessage{},
+	}
+
+	if params != nil {
+		body, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("could not marshal params, %w", err)
+		}
+
+		if err := json.Unmarshal(body, &req.fields); err != nil {
+			return fmt.Errorf("could not unmarshal params, %w", err)
+		}
+	}
+
+	d.Backend.mu.Lock()
+	res, err := routes[endpoint](d.Backend, req)
+	d.Backend.mu.Unlock()
+
+	if err != nil {
+		code := http.StatusInternalServerError
+		if e, ok := err.(*apiError); ok {
+			code = e.code
+		}
+		body, _ := json.Marshal(map[string]string{"status": "ERROR", "message": err.Error()})
+
+		return &porkbun.ApiError{Code: code, Body: string(body)}
+	}
+
+	res["status"] = "SUCCESS"
+	body, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, response)
+}
+
+func (d *DNS) CreateDnsRecord(ctx context.Context, domain string, params *porkbun.Record) (*porkbun.CreateDnsRecordResponse, error) {
+	var response porkbun.CreateDnsRecordResponse
+	if err := d.call(ctx, "dns/create", params, &response, domain); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (d *DNS) ListDnsRecords(ctx context.Context, domain, subdomain, recordType string) (*porkbun.DnsRecordsResponse, error) {
+	var response porkbun.DnsRecordsResponse
+
+	var err error
+	if recordType != "" {
+		err = d.call(ctx, "dns/retrieveByNameType", nil, &response, domain, recordType, subdomain)
+	} else {
+		err = d.call(ctx, "dns/retrieve", nil, &response, domain)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (d *DNS) GetDnsRecordById(ctx context.Context, domain string, id porkbun.RecordID) (*porkbun.DnsRecordsResponse, error) {
+	var response porkbun.DnsRecordsResponse
+	if err := d.call(ctx, "dns/retrieve", nil, &response, domain, id.String()); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (d *DNS) ModifyDnsRecord(ctx context.Context, domain string, record *porkbun.Record) (*porkbun.StatusResponse, error) {
+	var response porkbun.StatusResponse
+
+	var err error
+	if record.Id != "" {
+		err = d.call(ctx, "dns/edit", record, &response, domain, record.Id.String())
+	} else {
+		if record.Type == "" {
+			return nil, fmt.Errorf("record.Type must be set to modify this entry")
+		}
+		err = d.call(ctx, "dns/editByNameType", record, &response, domain, record.Type, record.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (d *DNS) DeleteDnsRecordById(ctx context.Context, domain string, id porkbun.RecordID) (*porkbun.StatusResponse, error) {
+	var response porkbun.StatusResponse
+	if err := d.call(ctx, "dns/delete", nil, &response, domain, id.String()); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (d *DNS) DeleteDnsRecordByLookup(ctx context.Context, domain, subdomain, recordType string) (*porkbun.StatusResponse, error) {
+	var response porkbun.StatusResponse
+	if err := d.call(ctx, "dns/deleteByNameType", nil, &response, domain, recordType, subdomain); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
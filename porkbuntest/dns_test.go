@@ -0,0 +1,94 @@
+package porkbuntest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestDNS(t *testing.T) {
+	ctx := context.TODO()
+	dns := porkbuntest.NewDNS(porkbuntest.WithDomains("example.com"))
+
+	var svc porkbun.DNSService = dns
+
+	created, err := svc.CreateDnsRecord(ctx, "example.com", &porkbun.Record{
+		Name:    "www",
+		Type:    "A",
+		Content: "10.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	res, err := svc.GetDnsRecordById(ctx, "example.com", created.Id)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if len(res.Records) != 1 || res.Records[0].Name != "www.example.com" {
+		t.Fatalf("got %+v, want the created record", res.Records)
+	}
+
+	if _, err := svc.ModifyDnsRecord(ctx, "example.com", &porkbun.Record{
+		Name:    "www",
+		Type:    "A",
+		Content: "10.0.0.2",
+	}); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	res, err = svc.ListDnsRecords(ctx, "example.com", "www", "A")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if len(res.Records) != 1 || res.Records[0].Content != "10.0.0.2" {
+		t.Fatalf("got %+v, want the modified record", res.Records)
+	}
+
+	if _, err := svc.DeleteDnsRecordById(ctx, "example.com", res.Records[0].Id); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	_, err = svc.DeleteDnsRecordByLookup(ctx, "example.com", "www", "A")
+
+	var apiErr *porkbun.ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want ApiError", err)
+	}
+
+	if apiErr.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want %d", apiErr.Code, http.StatusBadRequest)
+	}
+
+	if _, err := svc.ListDnsRecords(ctx, "example.org", "", ""); err == nil {
+		t.Errorf("expected error listing unknown domain")
+	}
+}
+
+func TestDNSUnexpectedError(t *testing.T) {
+	porkbuntest.SetRoute(t, "dns/retrieve", func(*porkbuntest.Backend) (map[string]any, error) {
+		return nil, errors.New("something broke")
+	})
+
+	dns := porkbuntest.NewDNS(porkbuntest.WithDomains("example.com"))
+	_, err := dns.ListDnsRecords(context.TODO(), "example.com", "", "")
+
+	var apiErr *porkbun.ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want ApiError", err)
+	}
+
+	if apiErr.Code != http.StatusInternalServerError {
+		t.Errorf("got %d, want %d", apiErr.Code, http.StatusInternalServerError)
+	}
+
+	if want := `{"message":"something broke","status":"ERROR"}`; apiErr.Body != want {
+		t.Errorf("got %s, want %s", apiErr.Body, want)
+	}
+}
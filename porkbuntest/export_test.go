@@ -0,0 +1,14 @@
+package porkbuntest
+
+import "testing"
+
+// SetRoute replaces the handler of an endpoint until the test ends.
+func SetRoute(t testing.TB, endpoint string, serve func(b *Backend) (map[string]any, error)) {
+	old := routes[endpoint]
+	routes[endpoint] = func(b *Backend, _ *request) (map[string]any, error) {
+		return serve(b)
+	}
+	t.Cleanup(func() {
+		routes[endpoint] = old
+	})
+}
@@ -0,0 +1,249 @@
+package porkbuntest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"time"
+)
+
+// Fault describes how a call misbehaves. The zero value is a healthy call.
+type Fault struct {
+	// Latency delays the response. The delay is cut short if the client gives
+	// up on the request.
+	Latency time.Duration
+
+	// Status replies with this HTTP status instead of handling the call. The
+	// call is not applied.
+	Status int
+
+	// Message is the error message sent in the Porkbun error envelope with
+	// Status.
+	Message string
+
+	// Body replaces the error envelope sent with Status, such as the HTML page
+	// of a load balancer.
+	Body string
+
+	// Malformed applies the call, then sends only the first half of the JSON
+	// response.
+	Malformed bool
+
+	// Drop closes the connection without a response. The call is not applied.
+	Drop bool
+
+	// DropResponse applies the call, then closes the connection without a
+	// response, so the client cannot tell whether it succeeded.
+	DropResponse bool
+
+	// NotApplied reports SUCCESS, but discards any changes the call made.
+	NotApplied bool
+}
+
+// Latency delays calls by d.
+func Latency(d time.Duration) Fault {
+	return Fault{Latency: d}
+}
+
+// ServerError fails calls with the given 5xx status and an HTML body, the way
+// Porkbun's load balancers do.
+func ServerError(code int) Fault {
+	return Fault{
+		Status: code,
+		Body:   "<html><body><h1>" + http.StatusText(code) + "</h1></body></html>",
+	}
+}
+
+// RateLimited rejects calls the way Porkbun does when a key sends too many
+// requests.
+func RateLimited() Fault {
+	return Fault{
+		Status:  http.StatusTooManyRequests,
+		Message: "Rate limit exceeded. Please try again later.",
+	}
+}
+
+// MalformedJSON applies calls, but truncates the response.
+func MalformedJSON() Fault {
+	return Fault{Malformed: true}
+}
+
+// DropConnection closes the connection before the call is applied.
+func DropConnection() Fault {
+	return Fault{Drop: true}
+}
+
+// NotApplied reports success without changing any state.
+func NotApplied() Fault {
+	return Fault{NotApplied: true}
+}
+
+// Rule injects a Fault into matching calls. Each rule counts the calls that
+// match its Endpoint; the fault fires on calls Start through
+// Start+Count-1, and within that window only on every Every-th call.
+type Rule struct {
+	// Endpoint is the path after /api/json/v3/ without its parameters, such
+	// as "dns/create" or "ping". Empty matches every endpoint.
+	Endpoint string
+
+	// Start is the first matching call, counting from 1, to fault. Zero is
+	// the same as 1.
+	Start int
+
+	// Count is the number of consecutive calls to fault. Zero faults every
+	// call from Start onwards.
+	Count int
+
+	// Every faults only every n-th call in the window. Zero or one faults
+	// each of them.
+	Every int
+
+	Fault Fault
+
+	calls int
+}
+
+func (r *Rule) trip(endpoint string) bool {
+	if r.Endpoint != "" && r.Endpoint != endpoint {
+		return false
+	}
+	r.calls++
+
+	start := max(r.Start, 1)
+	if r.calls < start {
+		return false
+	}
+
+	if r.Count > 0 && r.calls >= start+r.Count {
+		return false
+	}
+
+	return r.Every <= 1 || (r.calls-start+1)%r.Every == 0
+}
+
+// WithFaults injects faults from the start.
+func WithFaults(rules ...Rule) Option {
+	return func(b *Backend) {
+		b.Inject(rules...)
+	}
+}
+
+// Inject adds rules for misbehaving calls. When several rules match a call,
+// the first one added wins.
+func (b *Backend) Inject(rules ...Rule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, rule := range rules {
+		rule.calls = 0
+		b.rules = append(b.rules, &rule)
+	}
+}
+
+// ClearFaults removes every injected rule, healing the server.
+func (b *Backend) ClearFaults() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rules = nil
+}
+
+// Calls returns the number of calls received for an endpoint, such as
+// "dns/create", including faulted ones. Empty counts every endpoint.
+func (b *Backend) Calls(endpoint string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if endpoint == "" {
+		var total int
+		for _, n := range b.calls {
+			total += n
+		}
+		return total
+	}
+
+	return b.calls[endpoint]
+}
+
+// fault counts a call to endpoint and returns the fault to inject into it.
+func (b *Backend) fault(endpoint string) Fault {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.calls[endpoint]++
+
+	var fault Fault
+	var tripped bool
+	for _, rule := range b.rules {
+		// Every rule sees the call, so its count stays accurate.
+		if rule.trip(endpoint) && !tripped {
+			fault = rule.Fault
+			tripped = true
+		}
+	}
+
+	return fault
+}
+
+// injectFault wraps serve, which handles the call and discards its changes
+// when asked to, with the misbehaviour described by fault.
+func injectFault(w http.ResponseWriter, r *http.Request, fault Fault, serve func(w http.ResponseWriter, discard bool)) {
+	if fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case fault.Drop:
+		drop(w)
+	case fault.Status != 0:
+		if fault.Body != "" {
+			w.WriteHeader(fault.Status)
+			w.Write([]byte(fault.Body))
+			return
+		}
+		writeError(w, &apiError{code: fault.Status, message: fault.Message})
+	case fault.Malformed:
+		rec := httptest.NewRecorder()
+		serve(rec, false)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(rec.Code)
+		body := bytes.TrimSpace(rec.Body.Bytes())
+		w.Write(body[:len(body)/2])
+	case fault.DropResponse:
+		serve(httptest.NewRecorder(), false)
+		drop(w)
+	default:
+		serve(w, fault.NotApplied)
+	}
+}
+
+// drop closes the underlying connection without writing a response. Where the
+// connection cannot be taken over, such as over HTTP/2 or behind a wrapped
+// ResponseWriter, the response is aborted instead, which the client sees the
+// same way.
+func drop(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+}
+
+// clone deep copies every zone, so a call can be undone.
+func (b *Backend) clone() map[string]*zone {
+	zones := make(map[string]*zone, len(b.zones))
+	for name, z := range b.zones {
+		c := *z
+		c.records = slices.Clone(z.records)
+		c.forwards = slices.Clone(z.forwards)
+		c.nameservers = slices.Clone(z.nameservers)
+		zones[name] = &c
+	}
+
+	return zones
+}
@@ -0,0 +1,227 @@
+package porkbuntest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestFaults(t *testing.T) {
+	ctx := context.TODO()
+	record := &porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"}
+
+	t.Run("latency", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Fault: porkbuntest.Latency(time.Second),
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := newClient(t, server).Ping(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("server error burst", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Endpoint: "dns/create",
+			Count:    2,
+			Fault:    porkbuntest.ServerError(http.StatusBadGateway),
+		}))
+		defer server.Close()
+		server.Backend.AddDomain("example.com")
+		client := newClient(t, server)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.CreateDnsRecord(ctx, "example.com", record)
+
+			var apiErr *porkbun.ApiError
+			if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadGateway {
+				t.Fatalf("call %d: got %v, want %d", i+1, err, http.StatusBadGateway)
+			}
+		}
+
+		if _, err := client.CreateDnsRecord(ctx, "example.com", record); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if got := server.Backend.Calls("dns/create"); got != 3 {
+			t.Errorf("got %d calls, want 3", got)
+		}
+	})
+
+	t.Run("every nth call", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Endpoint: "ping",
+			Every:    3,
+			Fault:    porkbuntest.RateLimited(),
+		}))
+		defer server.Close()
+		client := newClient(t, server)
+
+		var failed []int
+		for i := 1; i <= 6; i++ {
+			if _, err := client.Ping(ctx); err != nil {
+				failed = append(failed, i)
+			}
+		}
+
+		if len(failed) != 2 || failed[0] != 3 || failed[1] != 6 {
+			t.Errorf("got failures on calls %v, want [3 6]", failed)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Fault: porkbuntest.RateLimited(),
+		}))
+		defer server.Close()
+		server.Backend.AddDomain("example.com")
+
+		_, err := newClient(t, server).ListDnsRecords(ctx, "example.com", "", "")
+
+		var apiErr *porkbun.ApiError
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+			t.Errorf("got %v, want %d", err, http.StatusTooManyRequests)
+		}
+	})
+
+	t.Run("malformed json", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Endpoint: "dns/create",
+			Fault:    porkbuntest.MalformedJSON(),
+		}))
+		defer server.Close()
+		server.Backend.AddDomain("example.com")
+
+		if _, err := newClient(t, server).CreateDnsRecord(ctx, "example.com", record); err == nil {
+			t.Errorf("expected error")
+		}
+
+		if records := server.Backend.Records("example.com"); len(records) != 1 {
+			t.Errorf("got %d records, want the call applied", len(records))
+		}
+	})
+
+	t.Run("dropped connection", func(t *testing.T) {
+		testCases := []struct {
+			msg   string
+			fault porkbuntest.Fault
+			want  int
+		}{
+			{msg: "before applying", fault: porkbuntest.DropConnection(), want: 0},
+			{msg: "after applying", fault: porkbuntest.Fault{DropResponse: true}, want: 1},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.msg, func(t *testing.T) {
+				server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+					Endpoint: "dns/create",
+					Fault:    tc.fault,
+				}))
+				defer server.Close()
+				server.Backend.AddDomain("example.com")
+
+				if _, err := newClient(t, server).CreateDnsRecord(ctx, "example.com", record); err == nil {
+					t.Errorf("expected error")
+				}
+
+				if records := server.Backend.Records("example.com"); len(records) != tc.want {
+					t.Errorf("got %d records, want %d", len(records), tc.want)
+				}
+			})
+		}
+	})
+
+	t.Run("dropped connection without hijacking", func(t *testing.T) {
+		backend := porkbuntest.NewBackend(
+			porkbuntest.WithDomains("example.com"),
+			porkbuntest.WithFaults(porkbuntest.Rule{Fault: porkbuntest.DropConnection()}),
+		)
+
+		// Hide the http.Hijacker of the ResponseWriter, like middleware does.
+		server := &porkbuntest.Server{
+			Server: httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				backend.ServeHTTP(struct{ http.ResponseWriter }{w}, r)
+			})),
+			Backend: backend,
+		}
+		var logs bytes.Buffer
+		server.Config.ErrorLog = log.New(&logs, "", 0)
+		server.Start()
+		defer server.Close()
+
+		if _, err := newClient(t, server).CreateDnsRecord(ctx, "example.com", record); err == nil {
+			t.Errorf("expected error")
+		}
+
+		// An aborted response is not logged; a handler that panicked is.
+		if logs.Len() > 0 {
+			t.Errorf("got %q, want no server errors", logs.String())
+		}
+
+		if records := backend.Records("example.com"); len(records) != 0 {
+			t.Errorf("got %d records, want 0", len(records))
+		}
+	})
+
+	t.Run("not applied", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Endpoint: "dns/create",
+			Count:    1,
+			Fault:    porkbuntest.NotApplied(),
+		}))
+		defer server.Close()
+		server.Backend.AddDomain("example.com")
+		client := newClient(t, server)
+
+		res, err := client.CreateDnsRecord(ctx, "example.com", record)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if res.Status != "SUCCESS" {
+			t.Errorf("got %s, want %s", res.Status, "SUCCESS")
+		}
+
+		if records := server.Backend.Records("example.com"); len(records) != 0 {
+			t.Errorf("got %d records, want 0", len(records))
+		}
+
+		if _, err := client.CreateDnsRecord(ctx, "example.com", record); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if records := server.Backend.Records("example.com"); len(records) != 1 {
+			t.Errorf("got %d records, want 1", len(records))
+		}
+	})
+
+	t.Run("clear faults", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithFaults(porkbuntest.Rule{
+			Fault: porkbuntest.ServerError(http.StatusServiceUnavailable),
+		}))
+		defer server.Close()
+		client := newClient(t, server)
+
+		if _, err := client.Ping(ctx); err == nil {
+			t.Errorf("expected error")
+		}
+
+		server.Backend.ClearFaults()
+
+		if _, err := client.Ping(ctx); err != nil {
+			t.Errorf("got %s, want nil", err)
+		}
+	})
+}
@@ -0,0 +1,445 @@
+package porkbuntest
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// listAll returns domains in pages of this size.
+const pageSize = 1000
+
+// apiError is reported to the caller in Porkbun's error envelope.
+type apiError struct {
+	code    int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(message string) *apiError {
+	return &apiError{code: http.StatusBadRequest, message: message}
+}
+
+// request is a decoded call to the API. Porkbun sends every parameter in the
+// JSON body, alongside the credentials.
+type request struct {
+	path   []string
+	fields map[string]json.RawMessage
+	remote string
+}
+
+// arg returns the n-th path segment after the endpoint name, or "".
+func (r *request) arg(n int) string {
+	if n+2 >= len(r.path) {
+		return ""
+	}
+
+	return r.path[n+2]
+}
+
+// field returns a body parameter as a string. Porkbun accepts numbers for
+// most parameters, so those are converted.
+func (r *request) field(name string) string {
+	raw, ok := r.fields[name]
+	if !ok {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+
+	return ""
+}
+
+func (r *request) record() (porkbun.Record, error) {
+	record := porkbun.Record{
+		Name:    r.field("name"),
+		Type:    r.field("type"),
+		Content: r.field("content"),
+		Notes:   r.field("notes"),
+	}
+
+	if raw, ok := r.fields["ttl"]; ok {
+		if err := json.Unmarshal(raw, &record.TTL); err != nil {
+			return record, badRequest("Invalid TTL.")
+		}
+	}
+	if raw, ok := r.fields["prio"]; ok {
+		if err := json.Unmarshal(raw, &record.Priority); err != nil {
+			return record, badRequest("Invalid priority.")
+		}
+	}
+
+	return record, nil
+}
+
+type handler func(b *Backend, req *request) (map[string]any, error)
+
+var routes = map[string]handler{
+	"ping":                    (*Backend).ping,
+	"dns/create":              (*Backend).dnsCreate,
+	"dns/edit":                (*Backend).dnsEdit,
+	"dns/editByNameType":      (*Backend).dnsEditByNameType,
+	"dns/delete":              (*Backend).dnsDelete,
+	"dns/deleteByNameType":    (*Backend).dnsDeleteByNameType,
+	"dns/retrieve":            (*Backend).dnsRetrieve,
+	"dns/retrieveByNameType":  (*Backend).dnsRetrieveByNameType,
+	"domain/listAll":          (*Backend).domainListAll,
+	"domain/getNs":            (*Backend).domainGetNs,
+	"domain/updateNs":         (*Backend).domainUpdateNs,
+	"domain/addUrlForward":    (*Backend).domainAddUrlForward,
+	"domain/getUrlForwarding": (*Backend).domainGetUrlForwarding,
+	"domain/deleteUrlForward": (*Backend).domainDeleteUrlForward,
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if e, ok := err.(*apiError); ok {
+		code = e.code
+	}
+
+	writeJSON(w, code, map[string]any{
+		"status":  "ERROR",
+		"message": err.Error(),
+	})
+}
+
+// ServeHTTP implements the Porkbun v3 JSON API.
+func (b *Backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, &apiError{code: http.StatusMethodNotAllowed, message: "All HTTP request must use POST."})
+		return
+	}
+
+	path, ok := strings.CutPrefix(strings.TrimLeft(r.URL.Path, "/"), "api/json/v3/")
+	if !ok {
+		writeError(w, &apiError{code: http.StatusNotFound, message: "Invalid endpoint."})
+		return
+	}
+
+	req := &request{
+		path:   strings.Split(path, "/"),
+		fields: map[string]json.RawMessage{},
+		remote: r.RemoteAddr,
+	}
+
+	endpoint := req.path[0]
+	if len(req.path) >= 2 && endpoint != "ping" {
+		endpoint += "/" + req.path[1]
+	}
+
+	route, ok := routes[endpoint]
+	if !ok {
+		writeError(w, &apiError{code: http.StatusNotFound, message: "Invalid endpoint."})
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req.fields); err != nil {
+		writeError(w, badRequest("Invalid JSON."))
+		return
+	}
+
+	injectFault(w, r, b.fault(endpoint), func(w http.ResponseWriter, discard bool) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if discard {
+			saved := b.clone()
+			defer func() { b.zones = saved }()
+		}
+
+		b.serve(w, req, route)
+	})
+}
+
+func (b *Backend) serve(w http.ResponseWriter, req *request, route handler) {
+	if req.field("apikey") != b.apiKey || req.field("secretapikey") != b.secretKey {
+		writeError(w, &apiError{code: http.StatusForbidden, message: "Invalid API key. (002)"})
+		return
+	}
+
+	res, err := route(b, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	res["status"] = "SUCCESS"
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (b *Backend) zone(domain string) (*zone, error) {
+	z, ok := b.zones[strings.ToLower(domain)]
+	if !ok {
+		return nil, badRequest("Invalid domain.")
+	}
+
+	return z, nil
+}
+
+func (b *Backend) ping(req *request) (map[string]any, error) {
+	ip := b.ip
+	if ip == "" {
+		ip, _, _ = net.SplitHostPort(req.remote)
+	}
+
+	return map[string]any{"yourIp": ip}, nil
+}
+
+func (b *Backend) dnsCreate(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := req.record()
+	if err != nil {
+		return nil, badRequest("Create error: " + err.Error())
+	}
+
+	id, err := b.createRecord(z, record)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"id": id}, nil
+}
+
+func (b *Backend) dnsEdit(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	i := z.find(porkbun.RecordID(req.arg(1)))
+	if i < 0 {
+		return nil, badRequest("Edit error: We were unable to edit the DNS record.")
+	}
+
+	record, err := req.record()
+	if err != nil {
+		return nil, badRequest("Edit error: " + err.Error())
+	}
+	record.Name = fqdn(record.Name, z.info.Domain)
+	if err := z.replace(i, record); err != nil {
+		return nil, badRequest("Edit error: " + err.Error())
+	}
+
+	return map[string]any{}, nil
+}
+
+// dnsEditByNameType sets the content, ttl and priority of every record with
+// the given subdomain and type.
+func (b *Backend) dnsEditByNameType(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	name := fqdn(req.arg(2), z.info.Domain)
+	indexes := z.match(name, req.arg(1))
+	if len(indexes) == 0 {
+		return nil, badRequest("Edit error: We were unable to edit the DNS record.")
+	}
+
+	for _, i := range indexes {
+		record, err := req.record()
+		if err != nil {
+			return nil, badRequest("Edit error: " + err.Error())
+		}
+		record.Name = name
+		record.Type = req.arg(1)
+		if err := z.replace(i, record, indexes...); err != nil {
+			return nil, badRequest("Edit error: " + err.Error())
+		}
+	}
+
+	return map[string]any{}, nil
+}
+
+func (b *Backend) dnsDelete(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	i := z.find(porkbun.RecordID(req.arg(1)))
+	if i < 0 {
+		return nil, badRequest("Delete error: Invalid record ID.")
+	}
+	z.remove(i)
+
+	return map[string]any{}, nil
+}
+
+func (b *Backend) dnsDeleteByNameType(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := z.match(fqdn(req.arg(2), z.info.Domain), req.arg(1))
+	if len(indexes) == 0 {
+		return nil, badRequest("Delete error: We were unable to delete the DNS record.")
+	}
+	z.remove(indexes...)
+
+	return map[string]any{}, nil
+}
+
+// dnsRetrieve returns every record on the domain, or the single record with
+// the given id. An unknown id is not an error; the list is just empty.
+func (b *Backend) dnsRetrieve(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	records := []porkbun.Record{}
+	for _, record := range z.records {
+		if id := porkbun.RecordID(req.arg(1)); id == "" || record.Id == id {
+			records = append(records, record)
+		}
+	}
+
+	return map[string]any{"records": records}, nil
+}
+
+func (b *Backend) dnsRetrieveByNameType(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	records := []porkbun.Record{}
+	for _, i := range z.match(fqdn(req.arg(2), z.info.Domain), req.arg(1)) {
+		records = append(records, z.records[i])
+	}
+
+	return map[string]any{"records": records}, nil
+}
+
+func (b *Backend) domainListAll(req *request) (map[string]any, error) {
+	start := 0
+	if s := req.field("start"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, badRequest("Invalid start.")
+		}
+		start = n
+	}
+
+	domains := []porkbun.Domain{}
+	for i, name := range b.domainNames() {
+		if i >= start && i < start+pageSize {
+			domains = append(domains, b.zones[name].info)
+		}
+	}
+
+	return map[string]any{"domains": domains}, nil
+}
+
+func (b *Backend) domainGetNs(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"ns": z.nameservers}, nil
+}
+
+func (b *Backend) domainUpdateNs(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	var ns []string
+	if err := json.Unmarshal(req.fields["ns"], &ns); err != nil || len(ns) == 0 {
+		return nil, badRequest("Invalid name servers.")
+	}
+	z.nameservers = ns
+
+	return map[string]any{}, nil
+}
+
+func (b *Backend) domainAddUrlForward(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	forward := porkbun.UrlForward{
+		Id:          strconv.Itoa(b.id()),
+		Subdomain:   req.field("subdomain"),
+		Location:    req.field("location"),
+		Type:        req.field("type"),
+		IncludePath: req.field("includePath"),
+		Wildcard:    req.field("wildcard"),
+	}
+
+	if forward.Location == "" {
+		return nil, badRequest("A location is required.")
+	}
+
+	if forward.Type != "temporary" && forward.Type != "permanent" {
+		return nil, badRequest("Invalid forward type.")
+	}
+
+	if forward.IncludePath == "" {
+		forward.IncludePath = "no"
+	}
+
+	if forward.Wildcard == "" {
+		forward.Wildcard = "no"
+	}
+
+	z.forwards = append(z.forwards, forward)
+
+	return map[string]any{}, nil
+}
+
+func (b *Backend) domainGetUrlForwarding(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"forwards": append([]porkbun.UrlForward{}, z.forwards...)}, nil
+}
+
+func (b *Backend) domainDeleteUrlForward(req *request) (map[string]any, error) {
+	z, err := b.zone(req.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, forward := range z.forwards {
+		if forward.Id == req.arg(1) {
+			z.forwards = append(z.forwards[:i], z.forwards[i+1:]...)
+			return map[string]any{}, nil
+		}
+	}
+
+	return nil, badRequest("Invalid forward ID.")
+}
@@ -0,0 +1,76 @@
+// Package porkbuntest provides a fake Porkbun API for tests.
+//
+// The fake keeps domains, DNS records and URL forwards in memory and serves
+// the v3 JSON endpoints that porkbun.Client talks to, so code built on the
+// client can be exercised end to end without real credentials.
+//
+//	server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+//	defer server.Close()
+//
+//	client, _ := porkbun.NewClient(
+//		porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+//		porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+//		porkbun.WithBaseUrl(server.URL),
+//	)
+package porkbuntest
+
+import (
+	"net/http/httptest"
+)
+
+const (
+	// DefaultApiKey is the api key accepted by a Backend unless overridden
+	// with WithCredentials.
+	DefaultApiKey = "apikey"
+
+	// DefaultSecretKey is the secret key accepted by a Backend unless
+	// overridden with WithCredentials.
+	DefaultSecretKey = "secretkey"
+)
+
+type Option func(*Backend)
+
+// WithCredentials sets the api and secret keys the fake accepts.
+func WithCredentials(apiKey, secretKey string) Option {
+	return func(b *Backend) {
+		b.apiKey = apiKey
+		b.secretKey = secretKey
+	}
+}
+
+// WithDomains registers domains on the account, with no records.
+func WithDomains(domains ...string) Option {
+	return func(b *Backend) {
+		for _, domain := range domains {
+			b.AddDomain(domain)
+		}
+	}
+}
+
+// WithIP sets the address returned by the ping endpoint. By default, the
+// remote address of the request is returned.
+func WithIP(ip string) Option {
+	return func(b *Backend) {
+		b.ip = ip
+	}
+}
+
+// Server is a Backend served over a local httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	// Backend holds the in-memory state of the server. Use it to seed and
+	// inspect domains and records.
+	Backend *Backend
+}
+
+// NewServer starts a fake Porkbun API. The caller should call Close when
+// finished.
+func NewServer(options ...Option) *Server {
+	backend := NewBackend(options...)
+
+	return &Server{
+		Server:  httptest.NewServer(backend),
+		Backend: backend,
+	}
+}
@@ -0,0 +1,355 @@
+package porkbuntest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+// newClient creates a client for the server. It does not validate records,
+// so requests reach the fake's own checks.
+func newClient(t *testing.T, server *porkbuntest.Server) *porkbun.Client {
+	t.Helper()
+
+	client, err := porkbun.NewClient(
+		porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+		porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+		porkbun.WithBaseUrl(server.URL),
+		porkbun.WithoutValidation(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+// post calls an endpoint directly, for the ones porkbun.Client doesn't wrap.
+func post(t *testing.T, server *porkbuntest.Server, endpoint string, body map[string]any) (int, map[string]any) {
+	t.Helper()
+
+	body["apikey"] = porkbuntest.DefaultApiKey
+	body["secretapikey"] = porkbuntest.DefaultSecretKey
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.Post(server.URL+endpoint, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var response map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	return res.StatusCode, response
+}
+
+func TestServerPing(t *testing.T) {
+	server := porkbuntest.NewServer(porkbuntest.WithIP("192.0.2.1"))
+	defer server.Close()
+
+	res, err := newClient(t, server).Ping(context.TODO())
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if res.YourIP != "192.0.2.1" {
+		t.Errorf("got %s, want %s", res.YourIP, "192.0.2.1")
+	}
+}
+
+func TestServerCredentials(t *testing.T) {
+	server := porkbuntest.NewServer(
+		porkbuntest.WithCredentials("other", "keys"),
+		porkbuntest.WithDomains("example.com"),
+	)
+	defer server.Close()
+
+	_, err := newClient(t, server).CreateDnsRecord(context.TODO(), "example.com", &porkbun.Record{
+		Type:    "A",
+		Content: "10.0.0.1",
+	})
+
+	var apiErr *porkbun.ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want ApiError", err)
+	}
+
+	if apiErr.Code != http.StatusForbidden {
+		t.Errorf("got %d, want %d", apiErr.Code, http.StatusForbidden)
+	}
+}
+
+func TestServerDns(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("create and retrieve", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+		defer server.Close()
+		client := newClient(t, server)
+
+		created, err := client.CreateDnsRecord(ctx, "example.com", &porkbun.Record{
+			Name:    "www",
+			Type:    "a",
+			Content: "10.0.0.1",
+			TTL:     300,
+		})
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		res, err := client.GetDnsRecordById(ctx, "example.com", created.Id)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if len(res.Records) != 1 {
+			t.Fatalf("got %d records, want 1", len(res.Records))
+		}
+
+		want := porkbun.Record{
+			Id:       res.Records[0].Id,
+			Name:     "www.example.com",
+			Type:     "A",
+			Content:  "10.0.0.1",
+			TTL:      600,
+			Priority: 0,
+		}
+		if res.Records[0] != want {
+			t.Errorf("got %+v, want %+v", res.Records[0], want)
+		}
+	})
+
+	t.Run("ids are unique", func(t *testing.T) {
+		server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+		defer server.Close()
+		client := newClient(t, server)
+
+		first, err := client.CreateDnsRecord(ctx, "example.com", &porkbun.Record{Type: "A", Content: "10.0.0.1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := client.CreateDnsRecord(ctx, "example.com", &porkbun.Record{Type: "A", Content: "10.0.0.2"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if first.Id == second.Id {
+			t.Errorf("got duplicate id %s", first.Id)
+		}
+	})
+
+	t.Run("duplicate create", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		server.Backend.AddRecord("example.com", porkbun.Record{Type: "A", Content: "10.0.0.1"})
+
+		_, err := newClient(t, server).CreateDnsRecord(ctx, "example.com", &porkbun.Record{Type: "A", Content: "10.0.0.1"})
+
+		var apiErr *porkbun.ApiError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("got %v, want ApiError", err)
+		}
+
+		if apiErr.Code != http.StatusBadRequest {
+			t.Errorf("got %d, want %d", apiErr.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("cname conflict", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"})
+
+		_, err := newClient(t, server).CreateDnsRecord(ctx, "example.com", &porkbun.Record{
+			Name:    "www",
+			Type:    "CNAME",
+			Content: "example.net",
+		})
+		if err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("retrieve by name and type", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		server.Backend.AddRecord("example.com", porkbun.Record{Type: "A", Content: "10.0.0.1"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "AAAA", Content: "::1"})
+		client := newClient(t, server)
+
+		testCases := []struct {
+			msg        string
+			subdomain  string
+			recordType string
+			want       int
+		}{
+			{msg: "all", subdomain: "", recordType: "", want: 4},
+			{msg: "root", subdomain: "", recordType: "A", want: 1},
+			{msg: "subdomain", subdomain: "www", recordType: "A", want: 2},
+			{msg: "no match", subdomain: "mail", recordType: "A", want: 0},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.msg, func(t *testing.T) {
+				res, err := client.ListDnsRecords(ctx, "example.com", tc.subdomain, tc.recordType)
+				if err != nil {
+					t.Fatalf("got %s, want nil", err)
+				}
+
+				if len(res.Records) != tc.want {
+					t.Errorf("got %d records, want %d", len(res.Records), tc.want)
+				}
+			})
+		}
+	})
+
+	t.Run("edit by name and type", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2"})
+		client := newClient(t, server)
+
+		_, err := client.ModifyDnsRecord(ctx, "example.com", &porkbun.Record{
+			Name:    "www",
+			Type:    "A",
+			Content: "10.0.0.9",
+			TTL:     1200,
+		})
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		for _, record := range server.Backend.Records("example.com") {
+			if record.Content != "10.0.0.9" || record.TTL != 1200 {
+				t.Errorf("got %+v, want content and ttl changed", record)
+			}
+		}
+
+		_, err = client.ModifyDnsRecord(ctx, "example.com", &porkbun.Record{
+			Name:    "mail",
+			Type:    "A",
+			Content: "10.0.0.9",
+		})
+		if err == nil {
+			t.Errorf("expected error editing missing record")
+		}
+	})
+
+	t.Run("edit by id", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		id, _ := server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.1"})
+
+		_, err := newClient(t, server).ModifyDnsRecord(ctx, "example.com", &porkbun.Record{
+			Id:      id,
+			Name:    "web",
+			Type:    "A",
+			Content: "10.0.0.2",
+		})
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		records := server.Backend.Records("example.com")
+		if records[0].Name != "web.example.com" || records[0].Content != "10.0.0.2" {
+			t.Errorf("got %+v, want renamed record", records[0])
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		server := porkbuntest.NewServer()
+		defer server.Close()
+		id, _ := server.Backend.AddRecord("example.com", porkbun.Record{Type: "A", Content: "10.0.0.1"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.2"})
+		server.Backend.AddRecord("example.com", porkbun.Record{Name: "www", Type: "A", Content: "10.0.0.3"})
+		client := newClient(t, server)
+
+		if _, err := client.DeleteDnsRecordById(ctx, "example.com", id); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if _, err := client.DeleteDnsRecordByLookup(ctx, "example.com", "www", "A"); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if records := server.Backend.Records("example.com"); len(records) != 0 {
+			t.Errorf("got %d records, want 0", len(records))
+		}
+
+		if _, err := client.DeleteDnsRecordById(ctx, "example.com", id); err == nil {
+			t.Errorf("expected error deleting missing record")
+		}
+	})
+}
+
+func TestServerDomain(t *testing.T) {
+	server := porkbuntest.NewServer(porkbuntest.WithDomains("example.net", "example.com"))
+	defer server.Close()
+
+	t.Run("list all", func(t *testing.T) {
+		code, res := post(t, server, "/api/json/v3/domain/listAll", map[string]any{})
+		if code != http.StatusOK {
+			t.Fatalf("got %d, want %d", code, http.StatusOK)
+		}
+
+		domains := res["domains"].([]any)
+		if len(domains) != 2 {
+			t.Fatalf("got %d domains, want 2", len(domains))
+		}
+
+		if got := domains[0].(map[string]any)["domain"]; got != "example.com" {
+			t.Errorf("got %s, want %s", got, "example.com")
+		}
+	})
+
+	t.Run("invalid domain", func(t *testing.T) {
+		code, res := post(t, server, "/api/json/v3/domain/getNs/example.org", map[string]any{})
+		if code != http.StatusBadRequest {
+			t.Errorf("got %d, want %d", code, http.StatusBadRequest)
+		}
+
+		if res["status"] != "ERROR" || res["message"] != "Invalid domain." {
+			t.Errorf("got %v, want error envelope", res)
+		}
+	})
+
+	t.Run("url forwarding", func(t *testing.T) {
+		code, _ := post(t, server, "/api/json/v3/domain/addUrlForward/example.com", map[string]any{
+			"subdomain": "blog",
+			"location":  "https://example.net",
+			"type":      "permanent",
+		})
+		if code != http.StatusOK {
+			t.Fatalf("got %d, want %d", code, http.StatusOK)
+		}
+
+		forwards := server.Backend.Forwards("example.com")
+		if len(forwards) != 1 || forwards[0].Location != "https://example.net" {
+			t.Fatalf("got %+v, want one forward", forwards)
+		}
+
+		code, _ = post(t, server, "/api/json/v3/domain/deleteUrlForward/example.com/"+forwards[0].Id, map[string]any{})
+		if code != http.StatusOK {
+			t.Fatalf("got %d, want %d", code, http.StatusOK)
+		}
+
+		if forwards := server.Backend.Forwards("example.com"); len(forwards) != 0 {
+			t.Errorf("got %d forwards, want 0", len(forwards))
+		}
+	})
+}
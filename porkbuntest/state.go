@@ -0,0 +1,126 @@
+package porkbuntest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// State is a serializable snapshot of a Backend, for seeding it from a
+// fixture and saving it between runs.
+type State struct {
+	Domains []DomainState `json:"domains"`
+}
+
+// DomainState is a domain and everything on it.
+type DomainState struct {
+	Domain string `json:"domain"`
+
+	// Records on the domain. Names may be relative to the domain, such as
+	// "www", or fully qualified. Records without an Id are given one.
+	Records []porkbun.Record `json:"records,omitempty"`
+
+	Forwards    []porkbun.UrlForward `json:"forwards,omitempty"`
+	Nameservers []string             `json:"nameservers,omitempty"`
+}
+
+// WithState seeds the backend, as with Restore. It panics if the state is
+// invalid, which is fine for fixtures in tests.
+func WithState(state State) Option {
+	return func(b *Backend) {
+		if err := b.Restore(state); err != nil {
+			panic("porkbuntest: invalid state: " + err.Error())
+		}
+	}
+}
+
+// State returns a snapshot of every domain on the account.
+func (b *Backend) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var state State
+	for _, name := range b.domainNames() {
+		z := b.zones[name]
+		state.Domains = append(state.Domains, DomainState{
+			Domain:      name,
+			Records:     append([]porkbun.Record(nil), z.records...),
+			Forwards:    append([]porkbun.UrlForward(nil), z.forwards...),
+			Nameservers: append([]string(nil), z.nameservers...),
+		})
+	}
+
+	return state
+}
+
+// Restore adds the domains in state to the account, keeping the ids of
+// records and forwards. Records are checked the same way the create endpoint
+// checks them.
+func (b *Backend) Restore(state State) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, domain := range state.Domains {
+		z := b.addDomain(domain.Domain)
+
+		if len(domain.Nameservers) > 0 {
+			z.nameservers = append([]string(nil), domain.Nameservers...)
+		}
+
+		for _, record := range domain.Records {
+			if err := b.restoreRecord(z, record); err != nil {
+				return err
+			}
+		}
+
+		for _, forward := range domain.Forwards {
+			if forward.Id == "" {
+				forward.Id = strconv.Itoa(b.id())
+			}
+			b.reserve(forward.Id)
+			z.forwards = append(z.forwards, forward)
+		}
+	}
+
+	return nil
+}
+
+func (b *Backend) restoreRecord(z *zone, record porkbun.Record) error {
+	name := strings.ToLower(strings.TrimSuffix(record.Name, "."))
+	if name == z.info.Domain {
+		name = ""
+	}
+	record.Name = strings.TrimSuffix(name, "."+z.info.Domain)
+
+	if record.Id == "" {
+		_, err := b.createRecord(z, record)
+		return err
+	}
+
+	if z.find(record.Id) >= 0 {
+		return badRequest("Duplicate record ID " + record.Id.String() + ".")
+	}
+
+	record.Name = fqdn(record.Name, z.info.Domain)
+	record, err := normalize(record)
+	if err != nil {
+		return err
+	}
+
+	if z.conflicts(record) {
+		return badRequest("Create error: We were unable to create the DNS record.")
+	}
+
+	b.reserve(record.Id.String())
+	z.records = append(z.records, record)
+
+	return nil
+}
+
+// reserve keeps new ids above an id that is already in use.
+func (b *Backend) reserve(id string) {
+	if n, err := strconv.Atoi(id); err == nil && n > b.nextID {
+		b.nextID = n
+	}
+}
@@ -0,0 +1,97 @@
+package porkbuntest_test
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestState(t *testing.T) {
+	fixture := porkbuntest.State{
+		Domains: []porkbuntest.DomainState{
+			{
+				Domain: "example.com",
+				Records: []porkbun.Record{
+					{Type: "A", Content: "10.0.0.1"},
+					{Name: "www", Type: "CNAME", Content: "example.com"},
+					{Id: "200000000", Name: "mail.example.com", Type: "MX", Content: "mx.example.net", Priority: 10},
+				},
+			},
+		},
+	}
+
+	server := porkbuntest.NewServer(porkbuntest.WithState(fixture))
+	defer server.Close()
+
+	records := server.Backend.Records("example.com")
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	if records[1].Name != "www.example.com" {
+		t.Errorf("got %s, want %s", records[1].Name, "www.example.com")
+	}
+
+	if records[2].Id != "200000000" || records[2].Name != "mail.example.com" {
+		t.Errorf("got %+v, want id and name kept", records[2])
+	}
+
+	created, err := newClient(t, server).CreateDnsRecord(context.TODO(), "example.com", &porkbun.Record{
+		Name:    "api",
+		Type:    "A",
+		Content: "10.0.0.2",
+	})
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if id, _ := strconv.Atoi(created.Id.String()); id <= 200000000 {
+		t.Errorf("got id %s, want ids above restored ones", created.Id)
+	}
+
+	restored := porkbuntest.NewBackend()
+	if err := restored.Restore(server.Backend.State()); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if got, want := restored.State(), server.Backend.State(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStateConflicts(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		records []porkbun.Record
+	}{
+		{
+			msg: "without ids",
+			records: []porkbun.Record{
+				{Name: "www", Type: "A", Content: "10.0.0.1"},
+				{Name: "www", Type: "CNAME", Content: "example.net"},
+			},
+		},
+		{
+			msg: "with ids",
+			records: []porkbun.Record{
+				{Id: "200000000", Name: "www", Type: "A", Content: "10.0.0.1"},
+				{Id: "200000001", Name: "www.example.com", Type: "CNAME", Content: "example.net"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			err := porkbuntest.NewBackend().Restore(porkbuntest.State{
+				Domains: []porkbuntest.DomainState{{Domain: "example.com", Records: tc.records}},
+			})
+			if err == nil {
+				t.Errorf("expected error for a CNAME next to an A record")
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Package psl finds the public suffix of a domain name, such as co.uk for
+// www.example.co.uk, from the Public Suffix List.
+//
+// A copy of the list from https://publicsuffix.org/list/ is embedded, and
+// used by Default. New suffixes are added to the list all the time, so a
+// fresher copy can be loaded with LoadFile, and made the default with
+// SetDefault.
+package psl
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/idna"
+)
+
+//go:embed public_suffix_list.dat
+var embedded []byte
+
+// List is a parsed Public Suffix List.
+type List struct {
+	// Rules by their text, such as "co.uk", "*.ck" or "!www.ck", with
+	// internationalized rules in punycode.
+	rules map[string]bool
+}
+
+// Parse reads a list in the format of public_suffix_list.dat: a rule per
+// line, and comments starting with //.
+func Parse(r io.Reader) (*List, error) {
+	list := &List{rules: map[string]bool{}}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		// Anything after the rule is ignored.
+		rule := strings.ToLower(strings.Fields(line)[0])
+
+		exception := strings.HasPrefix(rule, "!")
+		ascii, err := idna.Lookup.ToASCII(strings.TrimPrefix(strings.TrimPrefix(rule, "!"), "*."))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q, %w", rule, err)
+		}
+
+		switch {
+		case exception:
+			rule = "!" + ascii
+		case strings.HasPrefix(rule, "*."):
+			rule = "*." + ascii
+		default:
+			rule = ascii
+		}
+		list.rules[rule] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("err reading public suffix list, %w", err)
+	}
+
+	if len(list.rules) == 0 {
+		return nil, fmt.Errorf("public suffix list has no rules")
+	}
+
+	return list, nil
+}
+
+// LoadFile reads a list from a file, such as a fresh download of
+// https://publicsuffix.org/list/public_suffix_list.dat.
+func LoadFile(name string) (*List, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("err opening public suffix list, %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+var (
+	embeddedList = sync.OnceValue(func() *List {
+		list, err := Parse(bytes.NewReader(embedded))
+		if err != nil {
+			panic(fmt.Sprintf("psl: embedded list is invalid, %s", err))
+		}
+		return list
+	})
+
+	defaultList atomic.Pointer[List]
+)
+
+// Default returns the list set with SetDefault, or the embedded one.
+func Default() *List {
+	if list := defaultList.Load(); list != nil {
+		return list
+	}
+
+	return embeddedList()
+}
+
+// SetDefault replaces the list returned by Default. Nil goes back to the
+// embedded list.
+func SetDefault(list *List) {
+	defaultList.Store(list)
+}
+
+// PublicSuffix returns the public suffix of a domain name in punycode, such
+// as co.uk for www.example.co.uk. Names no rule matches are taken to end in a
+// top level domain, which is their suffix, as the list prescribes.
+func (l *List) PublicSuffix(name string) string {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(name, ".")), ".")
+
+	// Try the longest suffix first: the longest matching rule wins, and an
+	// exception is always longer than the wildcard it is an exception to.
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+		parent := strings.Join(labels[i+1:], ".")
+
+		switch {
+		case l.rules["!"+suffix]:
+			return parent
+		case l.rules[suffix]:
+			return suffix
+		case i+1 < len(labels) && l.rules["*."+parent]:
+			return suffix
+		}
+	}
+
+	return labels[len(labels)-1]
+}
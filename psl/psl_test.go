@@ -0,0 +1,94 @@
+package psl_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go/psl"
+)
+
+const rules = `// A comment
+com
+uk
+co.uk
+
+// Wildcards and exceptions
+*.ck
+!www.ck
+
+// Internationalized
+公司.cn
+cn
+`
+
+func TestPublicSuffix(t *testing.T) {
+	list, err := psl.Parse(strings.NewReader(rules))
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	testCases := []struct {
+		name   string
+		suffix string
+	}{
+		{name: "example.com", suffix: "com"},
+		{name: "www.example.com.", suffix: "com"},
+		{name: "www.example.co.uk", suffix: "co.uk"},
+		{name: "example.uk", suffix: "uk"},
+		{name: "Example.CO.UK", suffix: "co.uk"},
+		{name: "www.example.ck", suffix: "example.ck"},
+		{name: "www.ck", suffix: "ck"},
+		{name: "a.www.ck", suffix: "ck"},
+		{name: "example.xn--55qx5d.cn", suffix: "xn--55qx5d.cn"},
+		{name: "example.unlisted", suffix: "unlisted"},
+		{name: "com", suffix: "com"},
+	}
+
+	for _, tc := range testCases {
+		if got := list.PublicSuffix(tc.name); got != tc.suffix {
+			t.Errorf("got %q, want %q for %q", got, tc.suffix, tc.name)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if got := psl.Default().PublicSuffix("foo.example.co.uk"); got != "co.uk" {
+		t.Errorf("got %q, want %q from the embedded list", got, "co.uk")
+	}
+
+	name := filepath.Join(t.TempDir(), "public_suffix_list.dat")
+	if err := os.WriteFile(name, []byte("example.co.uk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := psl.LoadFile(name)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	psl.SetDefault(list)
+	defer psl.SetDefault(nil)
+
+	if got := psl.Default().PublicSuffix("foo.example.co.uk"); got != "example.co.uk" {
+		t.Errorf("got %q, want %q from the loaded list", got, "example.co.uk")
+	}
+
+	psl.SetDefault(nil)
+	if got := psl.Default().PublicSuffix("foo.example.co.uk"); got != "co.uk" {
+		t.Errorf("got %q, want %q after going back to the embedded list", got, "co.uk")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, content := range []string{"", "// only comments\n", "-bad-.com\n"} {
+		if _, err := psl.Parse(strings.NewReader(content)); err == nil {
+			t.Errorf("got nil, want an error for %q", content)
+		}
+	}
+
+	if _, err := psl.LoadFile(filepath.Join(t.TempDir(), "missing.dat")); err == nil {
+		t.Error("got nil, want an error for a missing file")
+	}
+}
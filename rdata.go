@@ -0,0 +1,331 @@
+package porkbun
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RecordData is the structured content of a record of a particular type.
+type RecordData interface {
+	// ToRecord builds the record at the given subdomain. The TTL is left for
+	// the caller to set.
+	ToRecord(name string) Record
+}
+
+// ParseRecord reads the content of an MX, SRV, CAA, TLSA, HTTPS or SVCB record
+// into an *MXData, *SRVData, *CAAData, *TLSAData or *SVCBData. Other types
+// have no structured form, and return an error.
+func ParseRecord(record Record) (RecordData, error) {
+	var data RecordData
+	var err error
+
+	switch recordType := strings.ToUpper(record.Type); recordType {
+	case "MX":
+		data = &MXData{Priority: record.Priority, Host: record.Content}
+	case "SRV":
+		data, err = parseSRV(record)
+	case "CAA":
+		data, err = parseCAA(record.Content)
+	case "TLSA":
+		data, err = parseTLSA(record.Content)
+	case "HTTPS", "SVCB":
+		data, err = parseSVCB(recordType, record.Content)
+	default:
+		return nil, fmt.Errorf("%q records have no structured form", record.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s content %q, %w", record.Type, record.Content, err)
+	}
+
+	return data, nil
+}
+
+type MXData struct {
+	Priority Priority `json:"priority"`
+
+	// The mail server, or "." for a domain that accepts no mail.
+	Host string `json:"host"`
+}
+
+func (d *MXData) ToRecord(name string) Record {
+	return Record{Name: name, Type: "MX", Content: d.Host, Priority: d.Priority}
+}
+
+type SRVData struct {
+	Priority Priority `json:"priority"`
+	Weight   uint16   `json:"weight"`
+	Port     uint16   `json:"port"`
+	Target   string   `json:"target"`
+}
+
+// ToRecord builds the record at name, which should hold the service and
+// protocol, as in "_sip._tcp". Porkbun keeps the priority out of the content.
+func (d *SRVData) ToRecord(name string) Record {
+	return Record{
+		Name:     name,
+		Type:     "SRV",
+		Content:  fmt.Sprintf("%d %d %s", d.Weight, d.Port, d.Target),
+		Priority: d.Priority,
+	}
+}
+
+func parseSRV(record Record) (*SRVData, error) {
+	fields := strings.Fields(record.Content)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("want \"weight port target\"")
+	}
+
+	weight, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight, %w", err)
+	}
+
+	port, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port, %w", err)
+	}
+
+	return &SRVData{
+		Priority: record.Priority,
+		Weight:   uint16(weight),
+		Port:     uint16(port),
+		Target:   fields[2],
+	}, nil
+}
+
+type CAAData struct {
+	Flag uint8 `json:"flag"`
+
+	// The property, such as "issue", "issuewild" or "iodef".
+	Tag string `json:"tag"`
+
+	// The value of the property, unquoted.
+	Value string `json:"value"`
+}
+
+func (d *CAAData) ToRecord(name string) Record {
+	value := strings.ReplaceAll(d.Value, `"`, `\"`)
+	return Record{Name: name, Type: "CAA", Content: fmt.Sprintf(`%d %s "%s"`, d.Flag, d.Tag, value)}
+}
+
+func parseCAA(content string) (*CAAData, error) {
+	fields := strings.SplitN(content, " ", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("want \"flag tag value\"")
+	}
+
+	flag, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flag, %w", err)
+	}
+
+	return &CAAData{Flag: uint8(flag), Tag: fields[1], Value: unquote(fields[2])}, nil
+}
+
+type TLSAUsage uint8
+
+const (
+	// The certificate chains up to this CA, which is also in the trust store.
+	TLSAUsagePKIXTA TLSAUsage = 0
+
+	// The certificate is this one, and is also trusted by the trust store.
+	TLSAUsagePKIXEE TLSAUsage = 1
+
+	// The certificate chains up to this CA, whatever the trust store says.
+	TLSAUsageDANETA TLSAUsage = 2
+
+	// The certificate is this one, whatever the trust store says. The usual
+	// choice for mail servers.
+	TLSAUsageDANEEE TLSAUsage = 3
+)
+
+type TLSASelector uint8
+
+const (
+	// Match the whole certificate.
+	TLSASelectorCert TLSASelector = 0
+
+	// Match only the public key, which survives renewals with the same key.
+	TLSASelectorSPKI TLSASelector = 1
+)
+
+type TLSAMatchingType uint8
+
+const (
+	TLSAMatchingFull   TLSAMatchingType = 0
+	TLSAMatchingSHA256 TLSAMatchingType = 1
+	TLSAMatchingSHA512 TLSAMatchingType = 2
+)
+
+type TLSAData struct {
+	Usage        TLSAUsage        `json:"usage"`
+	Selector     TLSASelector     `json:"selector"`
+	MatchingType TLSAMatchingType `json:"matchingType"`
+
+	// The certificate data, or its hash, as picked by the selector and the
+	// matching type.
+	Data []byte `json:"data"`
+}
+
+// ToRecord builds the record at name, which should hold the port and
+// protocol, as in "_25._tcp.mx".
+func (d *TLSAData) ToRecord(name string) Record {
+	return Record{
+		Name:    name,
+		Type:    "TLSA",
+		Content: fmt.Sprintf("%d %d %d %s", d.Usage, d.Selector, d.MatchingType, hex.EncodeToString(d.Data)),
+	}
+}
+
+func parseTLSA(content string) (*TLSAData, error) {
+	fields := strings.Fields(content)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("want \"usage selector matching-type data\"")
+	}
+
+	var numbers [3]uint8
+	for i, name := range []string{"usage", "selector", "matching type"} {
+		n, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s, %w", name, err)
+		}
+		numbers[i] = uint8(n)
+	}
+
+	data, err := hex.DecodeString(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid data, %w", err)
+	}
+
+	return &TLSAData{
+		Usage:        TLSAUsage(numbers[0]),
+		Selector:     TLSASelector(numbers[1]),
+		MatchingType: TLSAMatchingType(numbers[2]),
+		Data:         data,
+	}, nil
+}
+
+// SVCBParam is a key and value of an HTTPS or SVCB record, such as
+// alpn=h2,h3. Keys without a value, like no-default-alpn, have an empty
+// Value.
+type SVCBParam struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// SVCBData is the content of an HTTPS or SVCB record. Unlike MX and SRV, the
+// priority is part of the content. A priority of 0 is alias mode, and takes no
+// params.
+type SVCBData struct {
+	// "HTTPS" or "SVCB". Empty builds an SVCB record.
+	Type string `json:"type"`
+
+	Priority uint16      `json:"priority"`
+	Target   string      `json:"target"`
+	Params   []SVCBParam `json:"params,omitempty"`
+}
+
+// Param returns the value of the first param with the given key.
+func (d *SVCBData) Param(key string) (string, bool) {
+	for _, param := range d.Params {
+		if param.Key == key {
+			return param.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func (d *SVCBData) ToRecord(name string) Record {
+	recordType := strings.ToUpper(d.Type)
+	if recordType == "" {
+		recordType = "SVCB"
+	}
+
+	parts := []string{strconv.Itoa(int(d.Priority)), d.Target}
+	for _, param := range d.Params {
+		switch {
+		case param.Value == "":
+			parts = append(parts, param.Key)
+		case strings.ContainsAny(param.Value, ` "`):
+			parts = append(parts, param.Key+`="`+strings.ReplaceAll(param.Value, `"`, `\"`)+`"`)
+		default:
+			parts = append(parts, param.Key+"="+param.Value)
+		}
+	}
+
+	return Record{Name: name, Type: recordType, Content: strings.Join(parts, " ")}
+}
+
+func parseSVCB(recordType, content string) (*SVCBData, error) {
+	fields, err := splitQuoted(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("want \"priority target params...\"")
+	}
+
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid priority, %w", err)
+	}
+
+	data := &SVCBData{Type: recordType, Priority: uint16(priority), Target: fields[1]}
+	for _, field := range fields[2:] {
+		key, value, _ := strings.Cut(field, "=")
+		if key == "" {
+			return nil, fmt.Errorf("param %q has no key", field)
+		}
+		data.Params = append(data.Params, SVCBParam{Key: key, Value: unquote(value)})
+	}
+
+	return data, nil
+}
+
+// splitQuoted splits s on spaces, except inside double quotes. The quotes are
+// kept.
+func splitQuoted(s string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	var quoted, escaped bool
+
+	for _, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == ' ' && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+			continue
+		}
+		field.WriteRune(c)
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields, nil
+}
+
+// unquote strips the double quotes around s, if any, and unescapes the quotes
+// inside it.
+func unquote(s string) string {
+	if len(s) < 2 || !strings.HasPrefix(s, `"`) || !strings.HasSuffix(s, `"`) {
+		return s
+	}
+
+	return strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`)
+}
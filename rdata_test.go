@@ -0,0 +1,101 @@
+package porkbun_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+func TestRecordData(t *testing.T) {
+	testCases := []struct {
+		msg    string
+		data   porkbun.RecordData
+		record porkbun.Record
+	}{
+		{
+			msg:    "mx",
+			data:   &porkbun.MXData{Priority: 10, Host: "mx.example.net"},
+			record: porkbun.Record{Name: "", Type: "MX", Content: "mx.example.net", Priority: 10},
+		},
+		{
+			msg:    "srv",
+			data:   &porkbun.SRVData{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+			record: porkbun.Record{Name: "", Type: "SRV", Content: "5 5060 sip.example.com", Priority: 10},
+		},
+		{
+			msg:    "caa",
+			data:   &porkbun.CAAData{Flag: 128, Tag: "issue", Value: "letsencrypt.org; validationmethods=dns-01"},
+			record: porkbun.Record{Type: "CAA", Content: `128 issue "letsencrypt.org; validationmethods=dns-01"`},
+		},
+		{
+			msg:    "caa quotes",
+			data:   &porkbun.CAAData{Tag: "iodef", Value: `mailto:"ca"@example.com`},
+			record: porkbun.Record{Type: "CAA", Content: `0 iodef "mailto:\"ca\"@example.com"`},
+		},
+		{
+			msg: "tlsa",
+			data: &porkbun.TLSAData{
+				Usage:        porkbun.TLSAUsageDANEEE,
+				Selector:     porkbun.TLSASelectorSPKI,
+				MatchingType: porkbun.TLSAMatchingSHA256,
+				Data:         []byte{0xde, 0xad, 0xbe, 0xef},
+			},
+			record: porkbun.Record{Type: "TLSA", Content: "3 1 1 deadbeef"},
+		},
+		{
+			msg: "https",
+			data: &porkbun.SVCBData{
+				Type:     "HTTPS",
+				Priority: 1,
+				Target:   ".",
+				Params: []porkbun.SVCBParam{
+					{Key: "alpn", Value: "h2,h3"},
+					{Key: "no-default-alpn"},
+					{Key: "ech", Value: "a b"},
+				},
+			},
+			record: porkbun.Record{Type: "HTTPS", Content: `1 . alpn=h2,h3 no-default-alpn ech="a b"`},
+		},
+		{
+			msg:    "svcb alias",
+			data:   &porkbun.SVCBData{Type: "SVCB", Target: "svc.example.net"},
+			record: porkbun.Record{Type: "SVCB", Content: "0 svc.example.net"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			record := tc.data.ToRecord(tc.record.Name)
+			if record != tc.record {
+				t.Errorf("got %+v, want %+v", record, tc.record)
+			}
+
+			data, err := porkbun.ParseRecord(record)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if !reflect.DeepEqual(data, tc.data) {
+				t.Errorf("got %+v, want %+v", data, tc.data)
+			}
+		})
+	}
+}
+
+func TestParseRecordErrors(t *testing.T) {
+	for _, record := range []porkbun.Record{
+		{Type: "A", Content: "192.0.2.1"},
+		{Type: "SRV", Content: "10 5 5060 sip.example.com"},
+		{Type: "SRV", Content: "5 99999 sip.example.com"},
+		{Type: "CAA", Content: "issue letsencrypt.org"},
+		{Type: "TLSA", Content: "3 1 1 xyz"},
+		{Type: "TLSA", Content: "300 1 1 00"},
+		{Type: "HTTPS", Content: `1 . alpn="h2`},
+		{Type: "SVCB", Content: "."},
+	} {
+		if _, err := porkbun.ParseRecord(record); err == nil {
+			t.Errorf("got nil, want an error for %s %q", record.Type, record.Content)
+		}
+	}
+}
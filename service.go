@@ -0,0 +1,32 @@
+package porkbun
+
+import (
+	"context"
+)
+
+// DNSService manages the DNS records of domains. *Client implements it against
+// the Porkbun API; porkbuntest.DNS implements it in memory for tests.
+type DNSService interface {
+	CreateDnsRecord(ctx context.Context, domain string, params *Record) (*CreateDnsRecordResponse, error)
+	ListDnsRecords(ctx context.Context, domain, subdomain, recordType string) (*DnsRecordsResponse, error)
+	GetDnsRecordById(ctx context.Context, domain string, id RecordID) (*DnsRecordsResponse, error)
+	ModifyDnsRecord(ctx context.Context, domain string, record *Record) (*StatusResponse, error)
+	DeleteDnsRecordById(ctx context.Context, domain string, id RecordID) (*StatusResponse, error)
+	DeleteDnsRecordByLookup(ctx context.Context, domain, subdomain, recordType string) (*StatusResponse, error)
+}
+
+// DomainService manages the domains in an account, their name servers and URL
+// forwards.
+type DomainService interface {
+	ListDomains(ctx context.Context) (*DomainsResponse, error)
+	GetNameServers(ctx context.Context, domain string) (*NameServersResponse, error)
+	UpdateNameServers(ctx context.Context, domain string, nameServers []string) (*StatusResponse, error)
+	AddUrlForward(ctx context.Context, domain string, forward *UrlForward) (*StatusResponse, error)
+	GetUrlForwarding(ctx context.Context, domain string) (*UrlForwardsResponse, error)
+	DeleteUrlForward(ctx context.Context, domain, id string) (*StatusResponse, error)
+}
+
+var (
+	_ DNSService    = (*Client)(nil)
+	_ DomainService = (*Client)(nil)
+)
@@ -0,0 +1,332 @@
+package spf
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// MaxLookups is the most DNS lookups an SPF record may cause.
+const MaxLookups = 10
+
+// How deep includes and redirects are followed before giving up on a loop.
+const maxDepth = 10
+
+// Resolver looks up the DNS records an SPF record refers to. *net.Resolver
+// implements it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// DefaultResolver uses the system's DNS.
+var DefaultResolver Resolver = net.DefaultResolver
+
+// Lookup fetches and parses the SPF record published at domain.
+func Lookup(ctx context.Context, resolver Resolver, domain string) (*Record, error) {
+	txts, err := resolver.LookupTXT(ctx, domain)
+	if err != nil {
+		return nil, fmt.Errorf("err looking up spf record of %q, %w", domain, err)
+	}
+
+	var found *Record
+	for _, txt := range txts {
+		if !IsSPF(txt) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("found several spf records for %q", domain)
+		}
+
+		if found, err = Parse(txt); err != nil {
+			return nil, fmt.Errorf("err parsing spf record of %q, %w", domain, err)
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w for %q", ErrNoRecord, domain)
+	}
+
+	return found, nil
+}
+
+// CountLookups returns how many DNS lookups evaluating the record for domain
+// takes, following includes and redirects. More than MaxLookups makes the
+// record fail.
+func CountLookups(ctx context.Context, resolver Resolver, domain string, record *Record) (int, error) {
+	return countLookups(ctx, resolver, domain, record, 0)
+}
+
+func countLookups(ctx context.Context, resolver Resolver, domain string, record *Record, depth int) (int, error) {
+	if depth > maxDepth {
+		return 0, fmt.Errorf("includes of %q nest too deep, or loop", domain)
+	}
+
+	var count int
+	var targets []string
+	for _, m := range record.Mechanisms {
+		if !lookupMechanisms[m.Name] {
+			continue
+		}
+
+		count++
+		if m.Name == "include" {
+			targets = append(targets, m.Value)
+		}
+	}
+
+	// A redirect is only followed when nothing else matched, so there is no
+	// point to it after an all.
+	if target, ok := record.Modifier("redirect"); ok {
+		if _, hasAll := record.All(); !hasAll {
+			count++
+			targets = append(targets, target)
+		}
+	}
+
+	for _, target := range targets {
+		included, err := Lookup(ctx, resolver, target)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := countLookups(ctx, resolver, target, included, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, nil
+}
+
+// Flatten returns a copy of the record for domain with its includes, and its
+// a and mx mechanisms, replaced by the ip4 and ip6 networks they resolve to.
+// A redirect is flattened the same way, taking on the all of its target.
+// Mechanisms that cannot be flattened, like exists and ptr, are kept.
+//
+// Only the passing mechanisms of included records are kept, as include only
+// matches when the included record passes. A failing mechanism that would
+// have shadowed a later passing one inside an include is lost; SPF records
+// are rarely written that way.
+//
+// Flattened records have to be refreshed when the addresses behind them
+// change, so keep the original to flatten again.
+func Flatten(ctx context.Context, resolver Resolver, domain string, record *Record) (*Record, error) {
+	f := &flattener{resolver: resolver, seen: map[string]bool{}}
+	flat := &Record{}
+
+	for _, m := range record.Mechanisms {
+		if m.Qualifier != Pass || !flattens(m) {
+			if m.Name == "ip4" || m.Name == "ip6" {
+				f.seen[m.Value] = true
+			}
+			flat.Mechanisms = append(flat.Mechanisms, m)
+			continue
+		}
+
+		networks, err := f.resolve(ctx, domain, m, 0)
+		if err != nil {
+			return nil, err
+		}
+		flat.Mechanisms = f.add(flat.Mechanisms, networks...)
+	}
+
+	for _, m := range record.Modifiers {
+		if m.Name != "redirect" {
+			flat.Modifiers = append(flat.Modifiers, m)
+			continue
+		}
+
+		if _, hasAll := record.All(); hasAll {
+			continue
+		}
+
+		target, err := Lookup(ctx, resolver, m.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		networks, err := f.record(ctx, m.Value, target, 1)
+		if err != nil {
+			return nil, err
+		}
+		flat.Mechanisms = f.add(flat.Mechanisms, networks...)
+
+		if all, ok := target.All(); ok {
+			flat.Mechanisms = append(flat.Mechanisms, all)
+		}
+	}
+
+	return flat, nil
+}
+
+func flattens(m Mechanism) bool {
+	return m.Name == "include" || m.Name == "a" || m.Name == "mx"
+}
+
+type flattener struct {
+	resolver Resolver
+
+	// The networks already in the flattened record.
+	seen map[string]bool
+}
+
+// add appends the networks not already in the record.
+func (f *flattener) add(mechanisms []Mechanism, networks ...Mechanism) []Mechanism {
+	for _, network := range networks {
+		if f.seen[network.Value] {
+			continue
+		}
+		f.seen[network.Value] = true
+		mechanisms = append(mechanisms, network)
+	}
+
+	return mechanisms
+}
+
+// resolve returns the ip4 and ip6 mechanisms a passing include, a or mx
+// mechanism stands for.
+func (f *flattener) resolve(ctx context.Context, domain string, m Mechanism, depth int) ([]Mechanism, error) {
+	target := m.Value
+	if target == "" {
+		target = domain
+	}
+
+	switch m.Name {
+	case "include":
+		included, err := Lookup(ctx, f.resolver, target)
+		if err != nil {
+			return nil, err
+		}
+
+		return f.record(ctx, target, included, depth+1)
+	case "a":
+		return f.hosts(ctx, []string{target}, m.CIDR)
+	case "mx":
+		mxs, err := f.resolver.LookupMX(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("err looking up mx of %q, %w", target, err)
+		}
+
+		var hosts []string
+		for _, mx := range mxs {
+			hosts = append(hosts, mx.Host)
+		}
+
+		return f.hosts(ctx, hosts, m.CIDR)
+	default:
+		return []Mechanism{m}, nil
+	}
+}
+
+// record returns the networks an included record passes.
+func (f *flattener) record(ctx context.Context, domain string, record *Record, depth int) ([]Mechanism, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("includes of %q nest too deep, or loop", domain)
+	}
+
+	var networks []Mechanism
+	for _, m := range record.Mechanisms {
+		if m.Qualifier != Pass || m.Name == "all" {
+			continue
+		}
+
+		switch m.Name {
+		case "ip4", "ip6":
+			networks = append(networks, Mechanism{Qualifier: Pass, Name: m.Name, Value: m.Value})
+		case "include", "a", "mx":
+			resolved, err := f.resolve(ctx, domain, m, depth)
+			if err != nil {
+				return nil, err
+			}
+			networks = append(networks, resolved...)
+		default:
+			return nil, fmt.Errorf("cannot flatten %q included from %q", m, domain)
+		}
+	}
+
+	if target, ok := record.Modifier("redirect"); ok {
+		if _, hasAll := record.All(); !hasAll {
+			redirected, err := Lookup(ctx, f.resolver, target)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved, err := f.record(ctx, target, redirected, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			networks = append(networks, resolved...)
+		}
+	}
+
+	return networks, nil
+}
+
+// hosts returns the networks of the addresses of hosts, with the prefix
+// lengths of an a or mx mechanism.
+func (f *flattener) hosts(ctx context.Context, hosts []string, cidr string) ([]Mechanism, error) {
+	bits4, bits6, err := parseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	var networks []Mechanism
+	for _, host := range hosts {
+		addrs, err := f.resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("err looking up addresses of %q, %w", host, err)
+		}
+
+		for _, ipAddr := range addrs {
+			addr, ok := netip.AddrFromSlice(ipAddr.IP)
+			if !ok {
+				continue
+			}
+			addr = addr.Unmap()
+
+			name, bits := "ip4", bits4
+			if addr.Is6() {
+				name, bits = "ip6", bits6
+			}
+
+			value := addr.String()
+			if bits >= 0 && bits < addr.BitLen() {
+				value = netip.PrefixFrom(addr, bits).Masked().String()
+			}
+			networks = append(networks, Mechanism{Qualifier: Pass, Name: name, Value: value})
+		}
+	}
+
+	return networks, nil
+}
+
+// parseCIDR reads the "/24//64" prefix lengths of a and mx. Missing lengths
+// are -1.
+func parseCIDR(cidr string) (int, int, error) {
+	bits4, bits6 := -1, -1
+	if cidr == "" {
+		return bits4, bits6, nil
+	}
+
+	v4, v6, _ := strings.Cut(cidr, "//")
+
+	var err error
+	if v4 = strings.TrimPrefix(v4, "/"); v4 != "" {
+		if bits4, err = strconv.Atoi(v4); err != nil || bits4 > 32 {
+			return 0, 0, fmt.Errorf("invalid prefix length %q", cidr)
+		}
+	}
+	if v6 != "" {
+		if bits6, err = strconv.Atoi(v6); err != nil || bits6 > 128 {
+			return 0, 0, fmt.Errorf("invalid prefix length %q", cidr)
+		}
+	}
+
+	return bits4, bits6, nil
+}
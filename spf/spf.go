@@ -0,0 +1,246 @@
+// Package spf parses, checks and flattens SPF records, as published in TXT
+// records with porkbun.Client.
+//
+// An SPF record may cause at most 10 DNS lookups when it is evaluated,
+// counting those of every record it includes. CountLookups finds out how
+// many a record takes, and Flatten replaces includes with the addresses they
+// resolve to, so the record takes fewer. Split spreads a record that is too
+// long for one TXT string over a chain of records.
+package spf
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// The version tag every SPF record starts with.
+const version = "v=spf1"
+
+// ErrNoRecord is returned by Find when there is no SPF record.
+var ErrNoRecord = errors.New("no spf record")
+
+// Qualifier says what a matching mechanism means for the mail.
+type Qualifier byte
+
+const (
+	Pass     Qualifier = '+'
+	Fail     Qualifier = '-'
+	SoftFail Qualifier = '~'
+	Neutral  Qualifier = '?'
+)
+
+// Mechanisms that cost a DNS lookup when evaluated.
+var lookupMechanisms = map[string]bool{
+	"include": true,
+	"a":       true,
+	"mx":      true,
+	"ptr":     true,
+	"exists":  true,
+}
+
+var mechanisms = map[string]bool{
+	"all":     true,
+	"include": true,
+	"a":       true,
+	"mx":      true,
+	"ptr":     true,
+	"ip4":     true,
+	"ip6":     true,
+	"exists":  true,
+}
+
+type Mechanism struct {
+	Qualifier Qualifier `json:"qualifier"`
+
+	// The kind of mechanism, lowercase, such as "include" or "ip4".
+	Name string `json:"name"`
+
+	// What follows the colon, if anything: the domain of include, a, mx, ptr
+	// and exists, or the address or network of ip4 and ip6.
+	Value string `json:"value,omitempty"`
+
+	// The prefix lengths of a and mx, as in "/24" or "/24//64".
+	CIDR string `json:"cidr,omitempty"`
+}
+
+func (m Mechanism) String() string {
+	var s strings.Builder
+	if m.Qualifier != Pass && m.Qualifier != 0 {
+		s.WriteByte(byte(m.Qualifier))
+	}
+	s.WriteString(m.Name)
+	if m.Value != "" {
+		s.WriteString(":" + m.Value)
+	}
+	s.WriteString(m.CIDR)
+
+	return s.String()
+}
+
+// Modifier is a name=value term, such as redirect=_spf.example.com.
+type Modifier struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (m Modifier) String() string {
+	return m.Name + "=" + m.Value
+}
+
+// Record is a parsed SPF record. Modifiers are written after the mechanisms.
+type Record struct {
+	Mechanisms []Mechanism `json:"mechanisms"`
+	Modifiers  []Modifier  `json:"modifiers,omitempty"`
+}
+
+func (r *Record) String() string {
+	terms := []string{version}
+	for _, m := range r.Mechanisms {
+		terms = append(terms, m.String())
+	}
+	for _, m := range r.Modifiers {
+		terms = append(terms, m.String())
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// Modifier returns the value of the named modifier, if the record has it.
+func (r *Record) Modifier(name string) (string, bool) {
+	for _, m := range r.Modifiers {
+		if strings.EqualFold(m.Name, name) {
+			return m.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// All returns the all mechanism of the record, if it has one.
+func (r *Record) All() (Mechanism, bool) {
+	for _, m := range r.Mechanisms {
+		if m.Name == "all" {
+			return m, true
+		}
+	}
+
+	return Mechanism{}, false
+}
+
+// IsSPF reports whether TXT content is an SPF record.
+func IsSPF(content string) bool {
+	content = strings.ToLower(porkbun.JoinText(content))
+	return content == version || strings.HasPrefix(content, version+" ")
+}
+
+// Parse reads the content of an SPF TXT record. Content split into quoted
+// strings is joined first.
+func Parse(content string) (*Record, error) {
+	content = porkbun.JoinText(content)
+	if !IsSPF(content) {
+		return nil, fmt.Errorf("%q is not an spf record", content)
+	}
+
+	record := &Record{}
+	for _, term := range strings.Fields(content)[1:] {
+		if name, value, ok := parseModifier(term); ok {
+			record.Modifiers = append(record.Modifiers, Modifier{Name: name, Value: value})
+			continue
+		}
+
+		m, err := parseMechanism(term)
+		if err != nil {
+			return nil, err
+		}
+		record.Mechanisms = append(record.Mechanisms, m)
+	}
+
+	return record, nil
+}
+
+func parseModifier(term string) (string, string, bool) {
+	name, value, ok := strings.Cut(term, "=")
+	if !ok || name == "" || strings.ContainsAny(name, ":/") {
+		return "", "", false
+	}
+
+	return strings.ToLower(name), value, true
+}
+
+func parseMechanism(term string) (Mechanism, error) {
+	m := Mechanism{Qualifier: Pass}
+	switch Qualifier(term[0]) {
+	case Pass, Fail, SoftFail, Neutral:
+		m.Qualifier = Qualifier(term[0])
+		term = term[1:]
+	}
+
+	name, value, _ := strings.Cut(term, ":")
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name, m.CIDR = name[:i], name[i:]
+	}
+	m.Name = strings.ToLower(name)
+
+	if !mechanisms[m.Name] {
+		return m, fmt.Errorf("unknown mechanism %q", term)
+	}
+
+	switch m.Name {
+	case "a", "mx":
+		if i := strings.IndexByte(value, '/'); i >= 0 {
+			value, m.CIDR = value[:i], value[i:]
+		}
+	case "ip4", "ip6":
+		if _, err := parseNetwork(value); err != nil {
+			return m, fmt.Errorf("invalid %s %q, %w", m.Name, value, err)
+		}
+	case "include", "exists":
+		if value == "" {
+			return m, fmt.Errorf("%s needs a domain", m.Name)
+		}
+	}
+	m.Value = value
+
+	return m, nil
+}
+
+// parseNetwork reads an address or network, as in ip4 and ip6.
+func parseNetwork(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		return netip.ParsePrefix(value)
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Find returns the SPF record among TXT records, such as those returned by
+// ListDnsRecords for a name. It returns ErrNoRecord if there is none, and an
+// error if there are several, as a domain may only have one.
+func Find(records []porkbun.Record) (*porkbun.Record, error) {
+	var found *porkbun.Record
+	for i := range records {
+		if !strings.EqualFold(records[i].Type, "TXT") || !IsSPF(records[i].Content) {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("found several spf records for %q", records[i].Name)
+		}
+		found = &records[i]
+	}
+
+	if found == nil {
+		return nil, ErrNoRecord
+	}
+
+	return found, nil
+}
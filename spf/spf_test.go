@@ -0,0 +1,443 @@
+package spf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/spf"
+)
+
+// resolver answers lookups from maps, failing for names it doesn't know.
+type resolver struct {
+	txt  map[string][]string
+	addr map[string][]string
+	mx   map[string][]string
+}
+
+func (r *resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if txts, ok := r.txt[name]; ok {
+		return txts, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	addrs, ok := r.addr[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	var ips []net.IPAddr
+	for _, addr := range addrs {
+		ips = append(ips, net.IPAddr{IP: net.ParseIP(addr)})
+	}
+	return ips, nil
+}
+
+func (r *resolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	hosts, ok := r.mx[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	var mxs []*net.MX
+	for _, host := range hosts {
+		mxs = append(mxs, &net.MX{Host: host, Pref: 10})
+	}
+	return mxs, nil
+}
+
+func newResolver() *resolver {
+	return &resolver{
+		txt: map[string][]string{
+			"_spf.mail.example": {"v=spf1 include:_netblocks.mail.example ~all"},
+			"_netblocks.mail.example": {
+				"v=spf1 ip4:198.51.100.0/24 ip6:2001:db8:1::/48 -ip4:203.0.113.1 -all",
+				"google-site-verification=abc",
+			},
+			"send.example":          {"v=spf1 a mx/28 -all"},
+			"_spf.redirect.example": {"v=spf1 ip4:192.0.2.128/25 -all"},
+			"loop.example":          {"v=spf1 include:loop.example -all"},
+			"ptr.example":           {"v=spf1 ptr -all"},
+		},
+		addr: map[string][]string{
+			"send.example":     {"192.0.2.10", "2001:db8::10"},
+			"mx1.send.example": {"192.0.2.20"},
+			"mx2.send.example": {"192.0.2.21"},
+			"example.com":      {"203.0.113.5"},
+		},
+		mx: map[string][]string{
+			"send.example": {"mx1.send.example", "mx2.send.example"},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		content string
+		want    *spf.Record
+	}{
+		{
+			msg:     "mechanisms",
+			content: "v=spf1 a mx:mail.example.com/24 -ip4:192.0.2.1 ?ip6:2001:db8::/32 include:_spf.google.com ~all",
+			want: &spf.Record{Mechanisms: []spf.Mechanism{
+				{Qualifier: spf.Pass, Name: "a"},
+				{Qualifier: spf.Pass, Name: "mx", Value: "mail.example.com", CIDR: "/24"},
+				{Qualifier: spf.Fail, Name: "ip4", Value: "192.0.2.1"},
+				{Qualifier: spf.Neutral, Name: "ip6", Value: "2001:db8::/32"},
+				{Qualifier: spf.Pass, Name: "include", Value: "_spf.google.com"},
+				{Qualifier: spf.SoftFail, Name: "all"},
+			}},
+		},
+		{
+			msg:     "modifiers",
+			content: "V=SPF1 A/24//64 redirect=_spf.example.com exp=explain.example.com",
+			want: &spf.Record{
+				Mechanisms: []spf.Mechanism{{Qualifier: spf.Pass, Name: "a", CIDR: "/24//64"}},
+				Modifiers: []spf.Modifier{
+					{Name: "redirect", Value: "_spf.example.com"},
+					{Name: "exp", Value: "explain.example.com"},
+				},
+			},
+		},
+		{
+			msg:     "quoted chunks",
+			content: `"v=spf1 ip4:192.0.2.1 " "-all"`,
+			want: &spf.Record{Mechanisms: []spf.Mechanism{
+				{Qualifier: spf.Pass, Name: "ip4", Value: "192.0.2.1"},
+				{Qualifier: spf.Fail, Name: "all"},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			got, err := spf.Parse(tc.content)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+
+			again, err := spf.Parse(got.String())
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("got %+v %v from %q, want it to round trip", again, err, got)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, content := range []string{
+			"google-site-verification=abc",
+			"v=spf10 -all",
+			"v=spf1 ip4:300.0.0.1",
+			"v=spf1 include",
+			"v=spf1 allow",
+		} {
+			if _, err := spf.Parse(content); err == nil {
+				t.Errorf("got nil, want an error for %q", content)
+			}
+		}
+	})
+}
+
+func TestFind(t *testing.T) {
+	records := []porkbun.Record{
+		{Type: "TXT", Content: "google-site-verification=abc"},
+		{Type: "TXT", Content: "v=spf1 -all"},
+		{Type: "CNAME", Content: "v=spf1.example.com"},
+	}
+
+	got, err := spf.Find(records)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if got != &records[1] {
+		t.Errorf("got %+v, want %+v", got, records[1])
+	}
+
+	if _, err := spf.Find(records[:1]); !errors.Is(err, spf.ErrNoRecord) {
+		t.Errorf("got %v, want %s", err, spf.ErrNoRecord)
+	}
+
+	if _, err := spf.Find(append(records, porkbun.Record{Type: "TXT", Content: "v=spf1 ~all"})); err == nil {
+		t.Error("got nil, want an error for several records")
+	}
+}
+
+func TestCountLookups(t *testing.T) {
+	ctx := context.TODO()
+
+	testCases := []struct {
+		msg     string
+		content string
+		want    int
+	}{
+		{msg: "none", content: "v=spf1 ip4:192.0.2.1 -all", want: 0},
+		{msg: "nested includes", content: "v=spf1 include:_spf.mail.example -all", want: 2},
+		{msg: "a and mx", content: "v=spf1 a mx exists:%{i}.example.com include:send.example -all", want: 6},
+		{msg: "redirect", content: "v=spf1 a redirect=_spf.redirect.example", want: 2},
+		{msg: "redirect after all", content: "v=spf1 -all redirect=_spf.redirect.example", want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			record, err := spf.Parse(tc.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := spf.CountLookups(ctx, newResolver(), "example.com", record)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("loop", func(t *testing.T) {
+		record, _ := spf.Parse("v=spf1 include:loop.example -all")
+		if _, err := spf.CountLookups(ctx, newResolver(), "example.com", record); err == nil {
+			t.Error("got nil, want an error for a loop")
+		}
+	})
+
+	t.Run("missing include", func(t *testing.T) {
+		record, _ := spf.Parse("v=spf1 include:missing.example -all")
+		if _, err := spf.CountLookups(ctx, newResolver(), "example.com", record); err == nil {
+			t.Error("got nil, want an error for a missing include")
+		}
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	ctx := context.TODO()
+
+	testCases := []struct {
+		msg     string
+		content string
+		want    string
+	}{
+		{
+			msg:     "includes",
+			content: "v=spf1 ip4:198.51.100.0/24 include:_spf.mail.example include:send.example ~all",
+			want:    "v=spf1 ip4:198.51.100.0/24 ip6:2001:db8:1::/48 ip4:192.0.2.10 ip6:2001:db8::10 ip4:192.0.2.16/28 ~all",
+		},
+		{
+			msg:     "a and exists",
+			content: "v=spf1 a exists:%{i}.bl.example -all",
+			want:    "v=spf1 ip4:203.0.113.5 exists:%{i}.bl.example -all",
+		},
+		{
+			msg:     "redirect",
+			content: "v=spf1 ip4:192.0.2.1 redirect=_spf.redirect.example exp=explain.example",
+			want:    "v=spf1 ip4:192.0.2.1 ip4:192.0.2.128/25 -all exp=explain.example",
+		},
+		{
+			msg:     "failing include",
+			content: "v=spf1 -include:send.example -all",
+			want:    "v=spf1 -include:send.example -all",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			record, err := spf.Parse(tc.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			flat, err := spf.Flatten(ctx, newResolver(), "example.com", record)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if flat.String() != tc.want {
+				t.Errorf("got %q, want %q", flat, tc.want)
+			}
+		})
+	}
+
+	t.Run("ptr in include", func(t *testing.T) {
+		record, _ := spf.Parse("v=spf1 include:ptr.example -all")
+		if _, err := spf.Flatten(ctx, newResolver(), "example.com", record); err == nil {
+			t.Error("got nil, want an error for a ptr that cannot be flattened")
+		}
+	})
+}
+
+func TestSplit(t *testing.T) {
+	var networks []string
+	for i := 0; i < 40; i++ {
+		networks = append(networks, fmt.Sprintf("ip4:192.0.2.%d", i))
+	}
+
+	record, err := spf.Parse("v=spf1 " + strings.Join(networks, " ") + " -all exp=explain.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		parts, err := spf.Split(record, "", "example.com", 1000)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		want := []spf.Part{{Name: "", Content: record.String()}}
+		if !reflect.DeepEqual(parts, want) {
+			t.Errorf("got %+v, want %+v", parts, want)
+		}
+	})
+
+	t.Run("chained", func(t *testing.T) {
+		parts, err := spf.Split(record, "mail", "example.com", 0)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if len(parts) != 3 {
+			t.Fatalf("got %d parts, want 3", len(parts))
+		}
+
+		var got []string
+		for i, part := range parts {
+			if len(part.Content) > spf.DefaultMaxLength {
+				t.Errorf("got %d bytes in part %d, want at most %d", len(part.Content), i, spf.DefaultMaxLength)
+			}
+
+			if want := spf.ChainName("mail", i); i > 0 && part.Name != want {
+				t.Errorf("got name %q, want %q", part.Name, want)
+			}
+
+			parsed, err := spf.Parse(part.Content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range parsed.Mechanisms {
+				if m.Name == "ip4" {
+					got = append(got, m.String())
+				}
+			}
+		}
+
+		if !reflect.DeepEqual(got, networks) {
+			t.Errorf("got %v, want %v", got, networks)
+		}
+
+		if !strings.HasSuffix(parts[0].Content, " include:_spf1.mail.example.com -all exp=explain.example.com") {
+			t.Errorf("got %q, want it to include the chain, then the all", parts[0].Content)
+		}
+		if !strings.HasSuffix(parts[1].Content, " include:_spf2.mail.example.com") {
+			t.Errorf("got %q, want it to include the next part", parts[1].Content)
+		}
+		if strings.Contains(parts[2].Content, "include:") || strings.Contains(parts[2].Content, "all") {
+			t.Errorf("got %q, want only networks in the last part", parts[2].Content)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		if _, err := spf.Split(record, "", "example.com", 20); err == nil {
+			t.Error("got nil, want an error")
+		}
+	})
+
+	t.Run("same result", func(t *testing.T) {
+		content := "v=spf1 ip4:203.0.113.0/24 -ip4:192.0.2.5 ~ip4:198.51.100.0/24 " +
+			strings.Join(networks, " ") + " ip6:2001:db8::/32 include:_spf.example.net -all"
+		original, err := spf.Parse(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parts, err := spf.Split(original, "", "example.com", 0)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+		if len(parts) < 2 {
+			t.Fatalf("got %d parts, want a chain", len(parts))
+		}
+
+		zone := map[string]string{"_spf.example.net": "v=spf1 ip4:233.252.0.0/24 -all"}
+		chained := map[string]string{"_spf.example.net": zone["_spf.example.net"]}
+		zone["example.com"] = content
+		for _, part := range parts {
+			chained[strings.TrimPrefix(part.Name+".example.com", ".")] = part.Content
+		}
+
+		for _, ip := range []string{"192.0.2.5", "192.0.2.7", "198.51.100.1", "203.0.113.9", "2001:db8::1", "233.252.0.1", "10.0.0.1"} {
+			addr := netip.MustParseAddr(ip)
+			if got, want := evaluate(t, chained, "example.com", addr), evaluate(t, zone, "example.com", addr); got != want {
+				t.Errorf("%s: got %s, want %s", ip, got, want)
+			}
+		}
+	})
+
+	t.Run("kept in the first record", func(t *testing.T) {
+		for _, term := range []string{"-ip4:192.0.2.200", "a", "mx/24", "ptr", "exists:%{i}._spf.example.com"} {
+			terms := append(append([]string(nil), networks...), term)
+			kept, err := spf.Parse("v=spf1 " + strings.Join(terms, " ") + " -all")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := spf.Split(kept, "", "example.com", 0); err == nil {
+				t.Errorf("got nil, want an error for %q after the networks", term)
+			}
+
+			parts, err := spf.Split(kept, "", "example.com", 2000)
+			if err != nil || len(parts) != 1 {
+				t.Errorf("got %+v, %v, want one record for %q", parts, err, term)
+			}
+		}
+	})
+}
+
+// evaluate checks ip against the SPF record at name, following includes to
+// the records in zone, which may only use ip4, ip6, include and all.
+func evaluate(t *testing.T, zone map[string]string, name string, ip netip.Addr) string {
+	t.Helper()
+
+	record, err := spf.Parse(zone[name])
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+
+	results := map[spf.Qualifier]string{0: "pass", spf.Pass: "pass", spf.Fail: "fail", spf.SoftFail: "softfail", spf.Neutral: "neutral"}
+	for _, m := range record.Mechanisms {
+		var match bool
+		switch m.Name {
+		case "all":
+			match = true
+		case "ip4", "ip6":
+			prefix, err := netip.ParsePrefix(m.Value)
+			if err != nil {
+				addr := netip.MustParseAddr(m.Value)
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+			match = prefix.Contains(ip)
+		case "include":
+			match = evaluate(t, zone, m.Value, ip) == "pass"
+		default:
+			t.Fatalf("%s: cannot evaluate %s", name, m)
+		}
+
+		if match {
+			return results[m.Qualifier]
+		}
+	}
+
+	return "neutral"
+}
@@ -0,0 +1,136 @@
+package spf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxLength keeps each record of a chain within a single TXT string.
+const DefaultMaxLength = 255
+
+// Part is one record of a chain made by Split.
+type Part struct {
+	// The record name, relative to the domain: the name that was split for
+	// the first part, and "_spf1", "_spf2"... under it for the rest.
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// ChainName returns the name of the nth record of a chain under name, which
+// is relative to the domain; empty is the apex.
+func ChainName(name string, n int) string {
+	chain := "_spf" + strconv.Itoa(n)
+	if name == "" || name == "@" {
+		return chain
+	}
+
+	return chain + "." + name
+}
+
+// Split spreads a record at name, relative to domain, over as many records as
+// it takes to keep each within maxLength bytes. Each record includes the
+// next, and the first keeps the all and the modifiers. Every extra record
+// costs a DNS lookup. Zero or less uses DefaultMaxLength.
+//
+// Only mechanisms that mean the same inside an include move to the chain; see
+// chainable. The others stay in the first record, along with every mechanism
+// before them, so the mechanisms are still evaluated in the same order.
+func Split(record *Record, name, domain string, maxLength int) ([]Part, error) {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	var terms, tail []string
+	keep := 0
+	for _, m := range record.Mechanisms {
+		if m.Name == "all" {
+			tail = append(tail, m.String())
+			continue
+		}
+		terms = append(terms, m.String())
+		if !chainable(m) {
+			keep = len(terms)
+		}
+	}
+	for _, m := range record.Modifiers {
+		tail = append(tail, m.String())
+	}
+
+	var parts []Part
+	for n := 0; n == 0 || len(terms) > 0; n++ {
+		partName := name
+		if n > 0 {
+			partName = ChainName(name, n)
+		}
+
+		var last []string
+		if n == 0 {
+			last = tail
+		}
+		next := "include:" + fqdn(ChainName(name, n+1), domain)
+
+		fit := fits(terms, last, "", maxLength)
+		if fit < len(terms) {
+			fit = fits(terms, last, next, maxLength)
+			if n == 0 && fit < keep {
+				return nil, fmt.Errorf("%q has to stay in the first record, and does not fit in one of %d bytes with the terms before it", terms[keep-1], maxLength)
+			}
+			if fit == 0 {
+				return nil, fmt.Errorf("%q does not fit in a record of %d bytes", terms[0], maxLength)
+			}
+		}
+
+		content := append([]string{version}, terms[:fit]...)
+		if terms = terms[fit:]; len(terms) > 0 {
+			content = append(content, next)
+		}
+		content = append(content, last...)
+
+		parts = append(parts, Part{Name: partName, Content: strings.Join(content, " ")})
+	}
+
+	if len(parts[0].Content) > maxLength {
+		return nil, fmt.Errorf("%q does not fit in a record of %d bytes", parts[0].Content, maxLength)
+	}
+
+	return parts, nil
+}
+
+// chainable reports whether a mechanism means the same in a chained record as
+// in the record it came from. An include only matches when the included
+// record passes, so a mechanism that fails, soft fails or is neutral would no
+// longer do so. a, mx and ptr without a domain, and macros such as %{d}, refer
+// to the domain of the record they are in.
+func chainable(m Mechanism) bool {
+	return (m.Qualifier == Pass || m.Qualifier == 0) && m.Value != "" && !strings.Contains(m.Value, "%")
+}
+
+// fits returns how many terms fit in a record alongside the version, the
+// next include and the last terms.
+func fits(terms, last []string, next string, maxLength int) int {
+	length := len(version)
+	if next != "" {
+		length += 1 + len(next)
+	}
+	for _, term := range last {
+		length += 1 + len(term)
+	}
+
+	for i, term := range terms {
+		length += 1 + len(term)
+		if length > maxLength {
+			return i
+		}
+	}
+
+	return len(terms)
+}
+
+func fqdn(name, domain string) string {
+	if name == "" {
+		return domain
+	}
+
+	return name + "." + domain
+}
@@ -0,0 +1,124 @@
+package porkbun
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// TLSAName returns the record name for TLSA records of a service, such as
+// "_25._tcp.mx" for mail on port 25 of "mx". host is a subdomain or fully
+// qualified name; empty is the apex.
+func TLSAName(port uint16, protocol, host string) string {
+	name := fmt.Sprintf("_%d._%s", port, strings.ToLower(protocol))
+	if host == "" || host == "@" {
+		return name
+	}
+
+	return name + "." + host
+}
+
+// NewTLSAData builds the TLSA data for the first certificate or public key in
+// a PEM file. A public key only works with TLSASelectorSPKI.
+func NewTLSAData(pemData []byte, usage TLSAUsage, selector TLSASelector, matching TLSAMatchingType) (*TLSAData, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("err parsing certificate, %w", err)
+		}
+
+		return TLSADataFromCertificate(cert, usage, selector, matching)
+	case "PUBLIC KEY":
+		if selector != TLSASelectorSPKI {
+			return nil, fmt.Errorf("a public key can only be matched with selector %d", TLSASelectorSPKI)
+		}
+		if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("err parsing public key, %w", err)
+		}
+
+		return newTLSAData(block.Bytes, usage, selector, matching)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q, want a CERTIFICATE or PUBLIC KEY", block.Type)
+	}
+}
+
+// TLSADataFromCertificate builds the TLSA data for a certificate.
+func TLSADataFromCertificate(cert *x509.Certificate, usage TLSAUsage, selector TLSASelector, matching TLSAMatchingType) (*TLSAData, error) {
+	switch selector {
+	case TLSASelectorCert:
+		return newTLSAData(cert.Raw, usage, selector, matching)
+	case TLSASelectorSPKI:
+		return newTLSAData(cert.RawSubjectPublicKeyInfo, usage, selector, matching)
+	default:
+		return nil, fmt.Errorf("unknown selector %d", selector)
+	}
+}
+
+func newTLSAData(selected []byte, usage TLSAUsage, selector TLSASelector, matching TLSAMatchingType) (*TLSAData, error) {
+	if usage > TLSAUsageDANEEE {
+		return nil, fmt.Errorf("unknown usage %d", usage)
+	}
+
+	data := &TLSAData{Usage: usage, Selector: selector, MatchingType: matching}
+	switch matching {
+	case TLSAMatchingFull:
+		data.Data = selected
+	case TLSAMatchingSHA256:
+		sum := sha256.Sum256(selected)
+		data.Data = sum[:]
+	case TLSAMatchingSHA512:
+		sum := sha512.Sum512(selected)
+		data.Data = sum[:]
+	default:
+		return nil, fmt.Errorf("unknown matching type %d", matching)
+	}
+
+	return data, nil
+}
+
+// MatchesPEM reports whether any certificate or public key in a PEM file,
+// such as a certificate with its chain, matches the TLSA data. Use it after a
+// rotation to find records that are left over.
+func (d *TLSAData) MatchesPEM(pemData []byte) (bool, error) {
+	var found bool
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" && block.Type != "PUBLIC KEY" {
+			continue
+		}
+		found = true
+
+		if block.Type == "PUBLIC KEY" && d.Selector != TLSASelectorSPKI {
+			continue
+		}
+
+		want, err := NewTLSAData(pem.EncodeToMemory(block), d.Usage, d.Selector, d.MatchingType)
+		if err != nil {
+			return false, err
+		}
+
+		if bytes.Equal(want.Data, d.Data) {
+			return true, nil
+		}
+	}
+
+	if !found {
+		return false, fmt.Errorf("no certificate or public key found")
+	}
+
+	return false, nil
+}
@@ -0,0 +1,133 @@
+package porkbun_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+// newCertificate returns a self-signed certificate, and its PEM encoding.
+func newCertificate(t *testing.T) (*x509.Certificate, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mx.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestNewTLSAData(t *testing.T) {
+	cert, certPEM := newCertificate(t)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: cert.RawSubjectPublicKeyInfo})
+
+	spkiSHA256 := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	certSHA512 := sha512.Sum512(cert.Raw)
+
+	testCases := []struct {
+		msg      string
+		pem      []byte
+		selector porkbun.TLSASelector
+		matching porkbun.TLSAMatchingType
+		want     []byte
+	}{
+		{msg: "certificate spki sha256", pem: certPEM, selector: porkbun.TLSASelectorSPKI, matching: porkbun.TLSAMatchingSHA256, want: spkiSHA256[:]},
+		{msg: "certificate sha512", pem: certPEM, selector: porkbun.TLSASelectorCert, matching: porkbun.TLSAMatchingSHA512, want: certSHA512[:]},
+		{msg: "certificate full", pem: certPEM, selector: porkbun.TLSASelectorCert, matching: porkbun.TLSAMatchingFull, want: cert.Raw},
+		{msg: "public key", pem: keyPEM, selector: porkbun.TLSASelectorSPKI, matching: porkbun.TLSAMatchingSHA256, want: spkiSHA256[:]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			data, err := porkbun.NewTLSAData(tc.pem, porkbun.TLSAUsageDANEEE, tc.selector, tc.matching)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if !bytes.Equal(data.Data, tc.want) {
+				t.Errorf("got %x, want %x", data.Data, tc.want)
+			}
+		})
+	}
+
+	t.Run("record", func(t *testing.T) {
+		data, err := porkbun.NewTLSAData(certPEM, porkbun.TLSAUsageDANEEE, porkbun.TLSASelectorSPKI, porkbun.TLSAMatchingSHA256)
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		record := data.ToRecord(porkbun.TLSAName(25, "TCP", "mx"))
+		if record.Name != "_25._tcp.mx" || record.Type != "TLSA" {
+			t.Errorf("got %q %q, want %q %q", record.Name, record.Type, "_25._tcp.mx", "TLSA")
+		}
+
+		if err := record.Validate(); err != nil {
+			t.Errorf("got %s, want nil", err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := porkbun.NewTLSAData([]byte("not pem"), 3, 1, 1); err == nil {
+			t.Error("got nil, want an error for data without PEM")
+		}
+
+		if _, err := porkbun.NewTLSAData(keyPEM, 3, porkbun.TLSASelectorCert, 1); err == nil {
+			t.Error("got nil, want an error for a public key with the certificate selector")
+		}
+
+		if _, err := porkbun.NewTLSAData(certPEM, 3, 1, 7); err == nil {
+			t.Error("got nil, want an error for an unknown matching type")
+		}
+	})
+}
+
+func TestTLSAMatchesPEM(t *testing.T) {
+	_, oldPEM := newCertificate(t)
+	_, newPEM := newCertificate(t)
+	_, caPEM := newCertificate(t)
+
+	data, err := porkbun.NewTLSAData(oldPEM, porkbun.TLSAUsageDANEEE, porkbun.TLSASelectorSPKI, porkbun.TLSAMatchingSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := data.MatchesPEM(append(oldPEM, caPEM...)); err != nil || !ok {
+		t.Errorf("got %t %v, want true nil", ok, err)
+	}
+
+	if ok, err := data.MatchesPEM(append(newPEM, caPEM...)); err != nil || ok {
+		t.Errorf("got %t %v, want false nil", ok, err)
+	}
+
+	if _, err := data.MatchesPEM([]byte("not pem")); err == nil {
+		t.Error("got nil, want an error for data without PEM")
+	}
+}
@@ -0,0 +1,157 @@
+package porkbun
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The longest string a TXT record can hold in a single chunk, in bytes.
+const maxTXTChunk = 255
+
+// SplitText turns a TXT value into record content. A value that fits in one
+// string is returned as it is. A longer one is split into quoted strings of up
+// to 255 bytes, as in "abc" "def", with quotes and backslashes escaped. A value
+// that starts with a quote is always quoted, so it isn't mistaken for content
+// that is already split.
+//
+// Values are split between UTF-8 characters, never within one.
+func SplitText(value string) string {
+	if len(value) <= maxTXTChunk && !strings.HasPrefix(strings.TrimSpace(value), `"`) {
+		return value
+	}
+
+	var chunks []string
+	for {
+		end := min(len(value), maxTXTChunk)
+		for end < len(value) && end > 0 && !utf8.RuneStart(value[end]) {
+			end--
+		}
+
+		chunks = append(chunks, quoteText(value[:end]))
+		value = value[end:]
+
+		if value == "" {
+			break
+		}
+	}
+
+	return strings.Join(chunks, " ")
+}
+
+// JoinText reads TXT record content back into its value, whichever shape
+// Porkbun returns it in: bare, as in abc, quoted, as in "abc", or split, as in
+// "ab" "c" or "ab""c". Escaped quotes and backslashes are unescaped, as are
+// \DDD escapes of single bytes.
+//
+// Bare content is returned as it is, and so is quoted content that is not
+// well formed, such as a missing closing quote.
+func JoinText(content string) string {
+	chunks, err := textStrings(content)
+	if err != nil || chunks == nil {
+		return content
+	}
+
+	return strings.Join(chunks, "")
+}
+
+// TextValue returns the value of a TXT record, with the strings its content is
+// split into joined back together. See JoinText. Other records return Content.
+func (r Record) TextValue() string {
+	if !strings.EqualFold(r.Type, "TXT") {
+		return r.Content
+	}
+
+	return JoinText(r.Content)
+}
+
+// sameContent compares the content of two records of the same type. TXT
+// content is compared by value, however it is split, and host names whether
+// they are Unicode or punycode.
+func sameContent(recordType, a, b string) bool {
+	switch {
+	case strings.EqualFold(recordType, "TXT"):
+		return JoinText(a) == JoinText(b)
+	case hostContentTypes[strings.ToUpper(recordType)] && a != b:
+		asciiA, errA := ToASCII(a)
+		asciiB, errB := ToASCII(b)
+		return errA == nil && errB == nil && strings.EqualFold(asciiA, asciiB)
+	}
+
+	return a == b
+}
+
+// quoteText quotes one string of a TXT record.
+func quoteText(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// textStrings reads the strings of quoted TXT content, unescaped. It returns
+// nil for bare content.
+func textStrings(content string) ([]string, error) {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, `"`) {
+		return nil, nil
+	}
+
+	var chunks []string
+	for i := 0; i < len(content); {
+		switch content[i] {
+		case ' ', '\t':
+			i++
+			continue
+		case '"':
+		default:
+			return nil, fmt.Errorf("unexpected %q outside quotes at byte %d", content[i], i)
+		}
+
+		var chunk strings.Builder
+		i++
+		for {
+			if i >= len(content) {
+				return nil, fmt.Errorf("missing closing quote")
+			}
+
+			c := content[i]
+			if c == '"' {
+				i++
+				break
+			}
+			if c != '\\' {
+				chunk.WriteByte(c)
+				i++
+				continue
+			}
+
+			if i+1 >= len(content) {
+				return nil, fmt.Errorf("escape at the end of the content")
+			}
+			if i+3 < len(content) && isNumber(content[i+1:i+4]) {
+				n, _ := strconv.Atoi(content[i+1 : i+4])
+				if n > 255 {
+					return nil, fmt.Errorf("escape \\%s is not a byte", content[i+1:i+4])
+				}
+				chunk.WriteByte(byte(n))
+				i += 4
+				continue
+			}
+			chunk.WriteByte(content[i+1])
+			i += 2
+		}
+
+		chunks = append(chunks, chunk.String())
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,137 @@
+package porkbun_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestSplitText(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		value   string
+		content string
+	}{
+		{msg: "short", value: "v=spf1 -all", content: "v=spf1 -all"},
+		{msg: "short with quotes inside", value: `say "hi"`, content: `say "hi"`},
+		{msg: "leading quote", value: `"hi" there`, content: `"\"hi\" there"`},
+		{
+			msg:     "long",
+			value:   strings.Repeat("a", 300),
+			content: `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`,
+		},
+		{
+			msg:     "long with escapes",
+			value:   strings.Repeat("a", 254) + `"\`,
+			content: `"` + strings.Repeat("a", 254) + `\"" "\\"`,
+		},
+		{
+			msg:     "long with utf-8 at the boundary",
+			value:   strings.Repeat("a", 254) + "é",
+			content: `"` + strings.Repeat("a", 254) + `" "é"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			got := porkbun.SplitText(tc.value)
+			if got != tc.content {
+				t.Errorf("got %q, want %q", got, tc.content)
+			}
+
+			if joined := porkbun.JoinText(got); joined != tc.value {
+				t.Errorf("got %q, want %q to round trip", joined, tc.value)
+			}
+
+			record := porkbun.Record{Type: "TXT", Content: got}
+			if err := record.Validate(); err != nil {
+				t.Errorf("got %s, want nil", err)
+			}
+		})
+	}
+}
+
+func TestJoinText(t *testing.T) {
+	testCases := []struct {
+		msg     string
+		content string
+		value   string
+	}{
+		{msg: "bare", content: "v=spf1 -all", value: "v=spf1 -all"},
+		{msg: "bare with quotes inside", content: `say "hi"`, value: `say "hi"`},
+		{msg: "quoted", content: `"v=spf1 -all"`, value: "v=spf1 -all"},
+		{msg: "split", content: `"v=spf1 " "-all"`, value: "v=spf1 -all"},
+		{msg: "split without spaces", content: `"v=spf1 ""-all"`, value: "v=spf1 -all"},
+		{msg: "split with padding", content: ` "ab"  "c" `, value: "abc"},
+		{msg: "escaped quotes", content: `"say \"hi\""`, value: `say "hi"`},
+		{msg: "escaped backslash", content: `"a\\b"`, value: `a\b`},
+		{msg: "decimal escape", content: `"a\059b"`, value: "a;b"},
+		{msg: "empty string", content: `""`, value: ""},
+		{msg: "unterminated", content: `"a" "b`, value: `"a" "b`},
+		{msg: "text between strings", content: `"a" b "c"`, value: `"a" b "c"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			if got := porkbun.JoinText(tc.content); got != tc.value {
+				t.Errorf("got %q, want %q", got, tc.value)
+			}
+		})
+	}
+
+	t.Run("text value", func(t *testing.T) {
+		txt := porkbun.Record{Type: "txt", Content: `"a" "b"`}
+		if got := txt.TextValue(); got != "ab" {
+			t.Errorf("got %q, want %q", got, "ab")
+		}
+
+		caa := porkbun.Record{Type: "CAA", Content: `0 issue "letsencrypt.org"`}
+		if got := caa.TextValue(); got != caa.Content {
+			t.Errorf("got %q, want %q", got, caa.Content)
+		}
+	})
+}
+
+func TestClientSplitsText(t *testing.T) {
+	ctx := context.TODO()
+
+	server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	value := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 380)
+	record := &porkbun.Record{Name: "s1._domainkey", Type: "TXT", Content: value}
+
+	if _, err := client.CreateDnsRecord(ctx, "example.com", record); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if record.Content != value {
+		t.Errorf("got %q, want the record passed in to be left alone", record.Content)
+	}
+
+	res, err := client.ListDnsRecords(ctx, "example.com", "s1._domainkey", "TXT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Records) != 1 {
+		t.Fatalf("got %d records, want 1", len(res.Records))
+	}
+
+	if got := res.Records[0].Content; got != porkbun.SplitText(value) {
+		t.Errorf("got %q, want it split", got)
+	}
+	if got := res.Records[0].TextValue(); got != value {
+		t.Errorf("got %q, want %q", got, value)
+	}
+
+	ensured, err := client.EnsureDnsRecord(ctx, "example.com", record)
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if ensured.Action != porkbun.EnsureUnchanged {
+		t.Errorf("got %s, want %s for the same value split differently", ensured.Action, porkbun.EnsureUnchanged)
+	}
+}
@@ -0,0 +1,117 @@
+package porkbun
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RecordID identifies a DNS record. Porkbun returns ids as strings when
+// listing records but as numbers when creating them; both decode into a
+// RecordID.
+type RecordID string
+
+func (id *RecordID) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalLenient(data)
+	if err != nil {
+		return fmt.Errorf("invalid record id %s, %w", data, err)
+	}
+
+	*id = RecordID(s)
+	return nil
+}
+
+func (id RecordID) String() string {
+	return string(id)
+}
+
+// TTL is the time to live of a record, in seconds. Porkbun's minimum and
+// default is 600; zero leaves it to Porkbun.
+type TTL uint32
+
+// TTLFromDuration converts a duration to a TTL, rounding down to the second.
+func TTLFromDuration(d time.Duration) TTL {
+	return TTL(d / time.Second)
+}
+
+func (t TTL) Duration() time.Duration {
+	return time.Duration(t) * time.Second
+}
+
+// Porkbun sends and expects TTLs as strings.
+func (t TTL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(t), 10))
+}
+
+func (t *TTL) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalLenient(data)
+	if err != nil {
+		return fmt.Errorf("invalid ttl %s, %w", data, err)
+	}
+
+	if s == "" {
+		*t = 0
+		return nil
+	}
+
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid ttl %s, %w", data, err)
+	}
+
+	*t = TTL(n)
+	return nil
+}
+
+// Priority is the priority of an MX or SRV record. Zero leaves it to Porkbun.
+type Priority int
+
+// Porkbun sends and expects priorities as strings.
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.Itoa(int(p)))
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalLenient(data)
+	if err != nil {
+		return fmt.Errorf("invalid priority %s, %w", data, err)
+	}
+
+	if s == "" {
+		*p = 0
+		return nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid priority %s, %w", data, err)
+	}
+
+	*p = Priority(n)
+	return nil
+}
+
+// unmarshalLenient decodes a JSON string or number as a string. null decodes
+// as an empty string.
+func unmarshalLenient(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+
+	if bytes.Equal(data, []byte("null")) {
+		return "", nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		return s, err
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", err
+	}
+
+	return n.String(), nil
+}
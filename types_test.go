@@ -0,0 +1,95 @@
+package porkbun_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+func TestRecordJSON(t *testing.T) {
+	testCases := []struct {
+		msg  string
+		body string
+		want porkbun.Record
+	}{
+		{
+			msg:  "strings",
+			body: `{"id":"1234","ttl":"3600","prio":"10"}`,
+			want: porkbun.Record{Id: "1234", TTL: 3600, Priority: 10},
+		},
+		{
+			msg:  "numbers",
+			body: `{"id":1234,"ttl":3600,"prio":10}`,
+			want: porkbun.Record{Id: "1234", TTL: 3600, Priority: 10},
+		},
+		{
+			msg:  "null",
+			body: `{"id":"1234","ttl":null,"prio":null}`,
+			want: porkbun.Record{Id: "1234"},
+		},
+		{
+			msg:  "empty",
+			body: `{"id":"1234","ttl":"","prio":""}`,
+			want: porkbun.Record{Id: "1234"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			var got porkbun.Record
+			if err := json.Unmarshal([]byte(tc.body), &got); err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, body := range []string{`{"ttl":"soon"}`, `{"ttl":-1}`, `{"prio":"high"}`, `{"prio":1.5}`, `{"id":true}`} {
+			var got porkbun.Record
+			if err := json.Unmarshal([]byte(body), &got); err == nil {
+				t.Errorf("got nil, want an error for %s", body)
+			}
+		}
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		got, err := json.Marshal(porkbun.Record{Name: "www", TTL: 3600, Priority: 10})
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		want := `{"id":"","notes":"","name":"www","type":"","content":"","ttl":"3600","prio":"10"}`
+		if string(got) != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("encode defaults", func(t *testing.T) {
+		got, err := json.Marshal(porkbun.Record{Name: "www"})
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		want := `{"id":"","notes":"","name":"www","type":"","content":""}`
+		if string(got) != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+}
+
+func TestTTLDuration(t *testing.T) {
+	ttl := porkbun.TTLFromDuration(time.Hour + 500*time.Millisecond)
+	if ttl != 3600 {
+		t.Errorf("got %d, want %d", ttl, 3600)
+	}
+
+	if ttl.Duration() != time.Hour {
+		t.Errorf("got %s, want %s", ttl.Duration(), time.Hour)
+	}
+}
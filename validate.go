@@ -0,0 +1,310 @@
+package porkbun
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is returned when a record is rejected before it is sent to
+// Porkbun. It lists every problem found, not just the first.
+type ValidationError struct {
+	Record   Record
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf(
+		"invalid %s record %q: %s",
+		e.Record.Type,
+		e.Record.Name,
+		strings.Join(e.Problems, "; "),
+	)
+}
+
+// Validate checks the record the way Porkbun would, but with better messages:
+// the name follows the IDNA rules, the type is one Porkbun supports, the
+// content is well formed for the type, the TTL is not below the minimum, and
+// the priority of MX and SRV records is in range and not written into the
+// content instead. It returns a *ValidationError, or nil if the record is
+// valid.
+//
+// Whether a priority was given at all is not checked. An unset Priority is
+// zero, which is also a real priority, and the only one a null MX may have.
+func (r Record) Validate() error {
+	var problems []string
+	problem := func(format string, a ...any) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+
+	if _, err := ToASCII(r.Name); err != nil {
+		problem("%s", err)
+	}
+
+	recordType := strings.ToUpper(r.Type)
+	check, ok := contentChecks[recordType]
+	switch {
+	case r.Type == "":
+		problem("type is required")
+	case !ok:
+		problem("type %q is not supported", r.Type)
+	}
+
+	if r.Content == "" {
+		problem("content is required")
+	} else if ok {
+		for _, p := range check(r.Content) {
+			problem("%s", p)
+		}
+	}
+
+	if r.TTL != 0 && r.TTL < defaultTTL {
+		problem("ttl %d is below the minimum of %d", r.TTL, defaultTTL)
+	}
+
+	switch recordType {
+	case "MX", "SRV":
+		if r.Priority < 0 || r.Priority > 65535 {
+			problem("priority %d is out of range 0-65535", r.Priority)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Record: r, Problems: problems}
+	}
+
+	return nil
+}
+
+// contentChecks returns the problems with the content of each type Porkbun
+// supports.
+var contentChecks = map[string]func(content string) []string{
+	"A":     checkA,
+	"AAAA":  checkAAAA,
+	"CNAME": checkHostname,
+	"ALIAS": checkHostname,
+	"NS":    checkHostname,
+	"MX":    checkMX,
+	"SRV":   checkSRV,
+	"CAA":   checkCAA,
+	"TLSA":  checkTLSA,
+	"TXT":   checkTXT,
+	"HTTPS": checkSVCB,
+	"SVCB":  checkSVCB,
+}
+
+func checkA(content string) []string {
+	addr, err := netip.ParseAddr(content)
+	if err != nil || !addr.Is4() {
+		return []string{fmt.Sprintf("content %q is not an IPv4 address", content)}
+	}
+
+	return nil
+}
+
+func checkAAAA(content string) []string {
+	addr, err := netip.ParseAddr(content)
+	if err != nil || !addr.Is6() || addr.Zone() != "" {
+		return []string{fmt.Sprintf("content %q is not an IPv6 address", content)}
+	}
+
+	return nil
+}
+
+func checkHostname(content string) []string {
+	if !validHostname(content) {
+		return []string{fmt.Sprintf("content %q is not a hostname", content)}
+	}
+
+	return nil
+}
+
+func checkMX(content string) []string {
+	if fields := strings.Fields(content); len(fields) == 2 && isNumber(fields[0]) {
+		return []string{fmt.Sprintf("content %q includes a priority; set Priority instead", content)}
+	}
+
+	// A lone dot is a null MX, saying the domain accepts no mail.
+	if content == "." {
+		return nil
+	}
+
+	return checkHostname(content)
+}
+
+// checkSRV checks the "weight port target" layout Porkbun expects, with the
+// priority held separately.
+func checkSRV(content string) []string {
+	fields := strings.Fields(content)
+	switch {
+	case len(fields) == 4 && isNumber(fields[0]):
+		return []string{fmt.Sprintf("content %q includes a priority; set Priority instead, and keep weight, port and target", content)}
+	case len(fields) != 3:
+		return []string{fmt.Sprintf("content %q is not \"weight port target\"", content)}
+	}
+
+	var problems []string
+	if !inRange(fields[0], 65535) {
+		problems = append(problems, fmt.Sprintf("weight %q is out of range 0-65535", fields[0]))
+	}
+	if !inRange(fields[1], 65535) {
+		problems = append(problems, fmt.Sprintf("port %q is out of range 0-65535", fields[1]))
+	}
+	if fields[2] != "." && !validHostname(fields[2]) {
+		problems = append(problems, fmt.Sprintf("target %q is not a hostname", fields[2]))
+	}
+
+	return problems
+}
+
+// checkCAA checks the "flag tag value" layout, where value may be quoted.
+func checkCAA(content string) []string {
+	fields := strings.SplitN(content, " ", 3)
+	if len(fields) != 3 {
+		return []string{fmt.Sprintf("content %q is not \"flag tag value\"", content)}
+	}
+
+	var problems []string
+	if !inRange(fields[0], 255) {
+		problems = append(problems, fmt.Sprintf("flag %q is out of range 0-255", fields[0]))
+	}
+	if !validCAATag(fields[1]) {
+		problems = append(problems, fmt.Sprintf("tag %q is not letters and digits", fields[1]))
+	}
+
+	value := fields[2]
+	if strings.HasPrefix(value, `"`) {
+		if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+			problems = append(problems, fmt.Sprintf("value %s is missing its closing quote", value))
+		}
+		value = strings.Trim(value, `"`)
+	}
+	if value == "" && fields[1] != "issue" && fields[1] != "issuewild" {
+		problems = append(problems, "value is empty")
+	}
+
+	return problems
+}
+
+// checkTLSA checks the "usage selector matching-type data" layout, and that
+// the data is hex of the right length for the matching type.
+func checkTLSA(content string) []string {
+	fields := strings.Fields(content)
+	if len(fields) != 4 {
+		return []string{fmt.Sprintf("content %q is not \"usage selector matching-type data\"", content)}
+	}
+
+	var problems []string
+	if !inRange(fields[0], 3) {
+		problems = append(problems, fmt.Sprintf("usage %q is out of range 0-3", fields[0]))
+	}
+	if !inRange(fields[1], 1) {
+		problems = append(problems, fmt.Sprintf("selector %q is out of range 0-1", fields[1]))
+	}
+	if !inRange(fields[2], 2) {
+		problems = append(problems, fmt.Sprintf("matching type %q is out of range 0-2", fields[2]))
+	}
+
+	data, err := hex.DecodeString(fields[3])
+	if err != nil {
+		return append(problems, fmt.Sprintf("data %q is not hex", fields[3]))
+	}
+
+	want := map[string]int{"1": 32, "2": 64}[fields[2]]
+	if want != 0 && len(data) != want {
+		problems = append(problems, fmt.Sprintf("data is %d bytes, want %d for matching type %s", len(data), want, fields[2]))
+	}
+
+	return problems
+}
+
+func checkSVCB(content string) []string {
+	if _, err := parseSVCB("", content); err != nil {
+		return []string{fmt.Sprintf("content %q is invalid, %s", content, err)}
+	}
+
+	return nil
+}
+
+// checkTXT checks the strings of quoted TXT content, which must each fit in
+// 255 bytes. Longer bare content is fine, as the client splits it with
+// SplitText before sending it.
+func checkTXT(content string) []string {
+	chunks, err := textStrings(content)
+	if err != nil {
+		return []string{fmt.Sprintf("content is not well quoted, %s", err)}
+	}
+
+	var problems []string
+	for _, chunk := range chunks {
+		if len(chunk) > maxTXTChunk {
+			problems = append(problems, fmt.Sprintf("a string is %d bytes, longer than %d", len(chunk), maxTXTChunk))
+		}
+	}
+
+	return problems
+}
+
+// validHostname reports whether name is a valid, optionally fully qualified,
+// host name. Underscores are allowed, as in DKIM and SRV names.
+func validHostname(name string) bool {
+	// Internationalized names are checked in the punycode form they are sent
+	// in.
+	name, err := ToASCII(name)
+	if err != nil {
+		return false
+	}
+
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+
+		for _, c := range label {
+			if !isAlphaNumeric(c) && c != '-' && c != '_' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func validCAATag(tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	for _, c := range tag {
+		if !isAlphaNumeric(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAlphaNumeric(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// inRange reports whether s is a decimal number from 0 to limit.
+func inRange(s string, limit uint64) bool {
+	n, err := strconv.ParseUint(s, 10, 64)
+	return err == nil && n <= limit
+}
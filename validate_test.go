@@ -0,0 +1,208 @@
+package porkbun_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestValidate(t *testing.T) {
+	tlsaData := strings.Repeat("ab", 32)
+
+	testCases := []struct {
+		msg      string
+		record   porkbun.Record
+		problems []string
+	}{
+		{msg: "a", record: porkbun.Record{Type: "A", Content: "192.0.2.1"}},
+		{msg: "lowercase type", record: porkbun.Record{Type: "a", Content: "192.0.2.1"}},
+		{msg: "aaaa", record: porkbun.Record{Type: "AAAA", Content: "2001:db8::1"}},
+		{msg: "cname", record: porkbun.Record{Type: "CNAME", Content: "s1._domainkey.example.net."}},
+		{msg: "mx", record: porkbun.Record{Type: "MX", Content: "mx.example.net", Priority: 10}},
+		{msg: "null mx", record: porkbun.Record{Type: "MX", Content: "."}},
+		{msg: "srv", record: porkbun.Record{Type: "SRV", Content: "5 5060 sip.example.com", Priority: 10}},
+		{msg: "caa", record: porkbun.Record{Type: "CAA", Content: `0 issue "letsencrypt.org"`}},
+		{msg: "caa no issuer", record: porkbun.Record{Type: "CAA", Content: `0 issue ";"`}},
+		{msg: "tlsa", record: porkbun.Record{Type: "TLSA", Content: "3 1 1 " + tlsaData}},
+		{msg: "https", record: porkbun.Record{Type: "HTTPS", Content: "1 . alpn=h2,h3"}},
+		{msg: "txt", record: porkbun.Record{Type: "TXT", Content: "v=spf1 -all"}},
+		{msg: "long txt, split when sent", record: porkbun.Record{Type: "TXT", Content: strings.Repeat("a", 300)}},
+		{msg: "chunked txt", record: porkbun.Record{Type: "TXT", Content: `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("b", 100) + `"`}},
+		{msg: "ttl", record: porkbun.Record{Type: "A", Content: "192.0.2.1", TTL: 3600}},
+		{
+			msg:      "missing type and content",
+			record:   porkbun.Record{},
+			problems: []string{"type is required", "content is required"},
+		},
+		{
+			msg:      "unsupported type",
+			record:   porkbun.Record{Type: "SPF", Content: "v=spf1 -all"},
+			problems: []string{`type "SPF" is not supported`},
+		},
+		{
+			msg:      "a with ipv6",
+			record:   porkbun.Record{Type: "A", Content: "2001:db8::1"},
+			problems: []string{`content "2001:db8::1" is not an IPv4 address`},
+		},
+		{
+			msg:      "aaaa with ipv4",
+			record:   porkbun.Record{Type: "AAAA", Content: "192.0.2.1"},
+			problems: []string{`content "192.0.2.1" is not an IPv6 address`},
+		},
+		{
+			msg:      "cname with address",
+			record:   porkbun.Record{Type: "CNAME", Content: "http://example.com"},
+			problems: []string{`content "http://example.com" is not a hostname`},
+		},
+		{
+			msg:      "ns with bad label",
+			record:   porkbun.Record{Type: "NS", Content: "-ns1.example.com"},
+			problems: []string{`content "-ns1.example.com" is not a hostname`},
+		},
+		{
+			msg:      "mx with priority in content",
+			record:   porkbun.Record{Type: "MX", Content: "10 mx.example.net"},
+			problems: []string{`content "10 mx.example.net" includes a priority; set Priority instead`},
+		},
+		{
+			msg:      "mx priority out of range",
+			record:   porkbun.Record{Type: "MX", Content: "mx.example.net", Priority: 70000},
+			problems: []string{"priority 70000 is out of range 0-65535"},
+		},
+		{
+			msg:      "srv with priority in content",
+			record:   porkbun.Record{Type: "SRV", Content: "10 5 5060 sip.example.com"},
+			problems: []string{`content "10 5 5060 sip.example.com" includes a priority; set Priority instead, and keep weight, port and target`},
+		},
+		{
+			msg:      "srv fields",
+			record:   porkbun.Record{Type: "SRV", Content: "5 99999 sip_example!"},
+			problems: []string{`port "99999" is out of range 0-65535`, `target "sip_example!" is not a hostname`},
+		},
+		{
+			msg:      "srv layout",
+			record:   porkbun.Record{Type: "SRV", Content: "sip.example.com"},
+			problems: []string{`content "sip.example.com" is not "weight port target"`},
+		},
+		{
+			msg:      "caa fields",
+			record:   porkbun.Record{Type: "CAA", Content: `256 is-sue "letsencrypt.org`},
+			problems: []string{`flag "256" is out of range 0-255`, `tag "is-sue" is not letters and digits`, `value "letsencrypt.org is missing its closing quote`},
+		},
+		{
+			msg:      "tlsa fields",
+			record:   porkbun.Record{Type: "TLSA", Content: "4 2 3 " + tlsaData},
+			problems: []string{`usage "4" is out of range 0-3`, `selector "2" is out of range 0-1`, `matching type "3" is out of range 0-2`},
+		},
+		{
+			msg:      "tlsa data",
+			record:   porkbun.Record{Type: "TLSA", Content: "3 1 2 " + tlsaData},
+			problems: []string{"data is 32 bytes, want 64 for matching type 2"},
+		},
+		{
+			msg:      "tlsa not hex",
+			record:   porkbun.Record{Type: "TLSA", Content: "3 1 1 xyz"},
+			problems: []string{`data "xyz" is not hex`},
+		},
+		{
+			msg:      "svcb without target",
+			record:   porkbun.Record{Type: "SVCB", Content: "1"},
+			problems: []string{`content "1" is invalid, want "priority target params..."`},
+		},
+		{
+			msg:      "unterminated txt",
+			record:   porkbun.Record{Type: "TXT", Content: `"a" "b`},
+			problems: []string{"content is not well quoted, missing closing quote"},
+		},
+		{
+			msg:      "long txt chunk",
+			record:   porkbun.Record{Type: "TXT", Content: `"a" "` + strings.Repeat("b", 256) + `"`},
+			problems: []string{"a string is 256 bytes, longer than 255"},
+		},
+		{
+			msg:      "short ttl",
+			record:   porkbun.Record{Type: "A", Content: "192.0.2.1", TTL: 60},
+			problems: []string{"ttl 60 is below the minimum of 600"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			err := tc.record.Validate()
+			if tc.problems == nil {
+				if err != nil {
+					t.Errorf("got %s, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *porkbun.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a *ValidationError", err)
+			}
+
+			if !reflect.DeepEqual(validationErr.Problems, tc.problems) {
+				t.Errorf("got %q, want %q", validationErr.Problems, tc.problems)
+			}
+		})
+	}
+}
+
+func TestClientValidation(t *testing.T) {
+	ctx := context.TODO()
+
+	server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+	defer server.Close()
+
+	record := &porkbun.Record{Name: "www", Type: "A", Content: "not-an-address"}
+
+	t.Run("create", func(t *testing.T) {
+		var validationErr *porkbun.ValidationError
+		_, err := newTestClient(t, server).CreateDnsRecord(ctx, "example.com", record)
+		if !errors.As(err, &validationErr) {
+			t.Errorf("got %v, want a *ValidationError", err)
+		}
+
+		if calls := server.Backend.Calls("dns/create"); calls != 0 {
+			t.Errorf("got %d calls, want 0", calls)
+		}
+	})
+
+	t.Run("modify", func(t *testing.T) {
+		var validationErr *porkbun.ValidationError
+		_, err := newTestClient(t, server).ModifyDnsRecord(ctx, "example.com", record)
+		if !errors.As(err, &validationErr) {
+			t.Errorf("got %v, want a *ValidationError", err)
+		}
+
+		if calls := server.Backend.Calls("dns/editByNameType"); calls != 0 {
+			t.Errorf("got %d calls, want 0", calls)
+		}
+	})
+
+	t.Run("without validation", func(t *testing.T) {
+		client, err := porkbun.NewClient(
+			porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+			porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+			porkbun.WithBaseUrl(server.URL),
+			porkbun.WithoutValidation(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The fake is as lenient as Porkbun about content.
+		if _, err := client.CreateDnsRecord(ctx, "example.com", record); err != nil {
+			t.Errorf("got %s, want nil", err)
+		}
+
+		if calls := server.Backend.Calls("dns/create"); calls != 1 {
+			t.Errorf("got %d calls, want 1", calls)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultZoneCacheTTL is how long ResolveZone reuses the domain list, unless
+// the client is made WithZoneCacheTTL.
+const DefaultZoneCacheTTL = 5 * time.Minute
+
+// ErrNoZone is matched by a *NoZoneError with errors.Is.
+var ErrNoZone = errors.New("no zone in the account")
+
+// NoZoneError is returned by ResolveZone when none of the domains in the
+// account holds a name.
+type NoZoneError struct {
+	Name string
+}
+
+func (e *NoZoneError) Error() string {
+	return fmt.Sprintf("%s: %q is not in any domain", ErrNoZone, e.Name)
+}
+
+func (e *NoZoneError) Is(target error) bool {
+	return target == ErrNoZone
+}
+
+// ZoneName is a name split around the domain in the account it belongs to.
+type ZoneName struct {
+	// The name relative to the zone, such as "www" or "a.b". Empty for the
+	// zone itself.
+	Name string `json:"name"`
+
+	// The domain in the account, such as "example.co.uk".
+	Zone string `json:"zone"`
+}
+
+// String returns the full name.
+func (z ZoneName) String() string {
+	if z.Name == "" {
+		return z.Zone
+	}
+
+	return z.Name + "." + z.Zone
+}
+
+// zoneCache holds the domains in the account for ResolveZone.
+type zoneCache struct {
+	mu      sync.Mutex
+	zones   map[string]bool
+	expires time.Time
+}
+
+// WithZoneCacheTTL sets how long ResolveZone reuses the domain list before
+// listing the domains again. Zero lists them on every call.
+func WithZoneCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) error {
+		if ttl < 0 {
+			return fmt.Errorf("invalid zone cache ttl %s, it is negative", ttl)
+		}
+		c.zoneCacheTTL = ttl
+		return nil
+	}
+}
+
+// ResolveZone finds the domain in the account a fully qualified name belongs
+// to, and the name relative to it. The longest matching domain wins, so with
+// both example.com and dev.example.com in the account, www.dev.example.com is
+// "www" in dev.example.com.
+//
+// Unlike ParseDomain, the Public Suffix List has no say: the zones are the
+// domains from ListDomains, which is cached for the client's zone cache TTL.
+// A name in none of them returns a *NoZoneError.
+func (c *Client) ResolveZone(ctx context.Context, fqdn string) (*ZoneName, error) {
+	name, err := ToASCII(strings.TrimSuffix(fqdn, "."))
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+
+	zones, err := c.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try the longest candidate first, dropping a label at a time.
+	for candidate, relative := name, ""; candidate != ""; {
+		if zones[candidate] {
+			return &ZoneName{Name: relative, Zone: candidate}, nil
+		}
+
+		label, rest, found := strings.Cut(candidate, ".")
+		if !found {
+			break
+		}
+
+		if relative == "" {
+			relative = label
+		} else {
+			relative += "." + label
+		}
+		candidate = rest
+	}
+
+	return nil, &NoZoneError{Name: fqdn}
+}
+
+// zones returns the domains in the account, from the cache if it is fresh.
+func (c *Client) zones(ctx context.Context) (map[string]bool, error) {
+	c.zoneCache.mu.Lock()
+	defer c.zoneCache.mu.Unlock()
+
+	if c.zoneCache.zones != nil && time.Now().Before(c.zoneCache.expires) {
+		return c.zoneCache.zones, nil
+	}
+
+	res, err := c.ListDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("err listing zones, %w", err)
+	}
+
+	zones := make(map[string]bool, len(res.Domains))
+	for _, domain := range res.Domains {
+		zones[strings.ToLower(strings.TrimSuffix(domain.Domain, "."))] = true
+	}
+
+	c.zoneCache.zones = zones
+	c.zoneCache.expires = time.Now().Add(c.zoneCacheTTL)
+
+	return zones, nil
+}
@@ -0,0 +1,116 @@
+package porkbun_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+	"github.com/andrew-womeldorf/porkbun-go/porkbuntest"
+)
+
+func TestResolveZone(t *testing.T) {
+	ctx := context.TODO()
+
+	server := porkbuntest.NewServer(porkbuntest.WithDomains(
+		"example.com",
+		"dev.example.com",
+		"example.co.uk",
+		"us.com",
+		"xn--bcher-kva.de",
+	))
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	testCases := []struct {
+		msg  string
+		fqdn string
+		want porkbun.ZoneName
+	}{
+		{msg: "apex", fqdn: "example.com", want: porkbun.ZoneName{Zone: "example.com"}},
+		{msg: "subdomain", fqdn: "www.example.com", want: porkbun.ZoneName{Name: "www", Zone: "example.com"}},
+		{msg: "trailing dot and case", fqdn: "WWW.Example.COM.", want: porkbun.ZoneName{Name: "www", Zone: "example.com"}},
+		{msg: "longest match", fqdn: "a.b.dev.example.com", want: porkbun.ZoneName{Name: "a.b", Zone: "dev.example.com"}},
+		{msg: "nested zone apex", fqdn: "dev.example.com", want: porkbun.ZoneName{Zone: "dev.example.com"}},
+		{msg: "multi-label suffix", fqdn: "_dmarc.example.co.uk", want: porkbun.ZoneName{Name: "_dmarc", Zone: "example.co.uk"}},
+		{msg: "zone on a public suffix", fqdn: "shop.us.com", want: porkbun.ZoneName{Name: "shop", Zone: "us.com"}},
+		{msg: "unicode", fqdn: "www.bücher.de", want: porkbun.ZoneName{Name: "www", Zone: "xn--bcher-kva.de"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.msg, func(t *testing.T) {
+			got, err := client.ResolveZone(ctx, tc.fqdn)
+			if err != nil {
+				t.Fatalf("got %s, want nil", err)
+			}
+
+			if *got != tc.want {
+				t.Errorf("got %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		if calls := server.Backend.Calls("domain/listAll"); calls != 1 {
+			t.Errorf("got %d domain list calls, want 1", calls)
+		}
+	})
+
+	t.Run("not owned", func(t *testing.T) {
+		for _, fqdn := range []string{"example.net", "com", "notexample.com"} {
+			_, err := client.ResolveZone(ctx, fqdn)
+
+			var noZone *porkbun.NoZoneError
+			if !errors.As(err, &noZone) || !errors.Is(err, porkbun.ErrNoZone) {
+				t.Errorf("got %v, want a *NoZoneError for %q", err, fqdn)
+			}
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		got := porkbun.ZoneName{Name: "a.b", Zone: "dev.example.com"}
+		if got.String() != "a.b.dev.example.com" {
+			t.Errorf("got %q, want %q", got, "a.b.dev.example.com")
+		}
+	})
+}
+
+func TestResolveZoneCacheTTL(t *testing.T) {
+	ctx := context.TODO()
+
+	server := porkbuntest.NewServer(porkbuntest.WithDomains("example.com"))
+	defer server.Close()
+
+	client, err := porkbun.NewClient(
+		porkbun.WithApiKey(porkbuntest.DefaultApiKey),
+		porkbun.WithSecretKey(porkbuntest.DefaultSecretKey),
+		porkbun.WithBaseUrl(server.URL),
+		porkbun.WithZoneCacheTTL(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ResolveZone(ctx, "www.example.net"); !errors.Is(err, porkbun.ErrNoZone) {
+		t.Fatalf("got %v, want ErrNoZone", err)
+	}
+
+	// Without a cache, a domain added to the account is found straight away.
+	server.Backend.AddDomain("example.net")
+
+	got, err := client.ResolveZone(ctx, "www.example.net")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+	if got.Zone != "example.net" {
+		t.Errorf("got %s, want %s", got.Zone, "example.net")
+	}
+
+	if calls := server.Backend.Calls("domain/listAll"); calls != 2 {
+		t.Errorf("got %d domain list calls, want 2", calls)
+	}
+
+	if _, err := porkbun.NewClient(porkbun.WithZoneCacheTTL(-1)); err == nil {
+		t.Error("got nil, want an error for a negative ttl")
+	}
+}
@@ -0,0 +1,744 @@
+package porkbun
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// ZoneFileOption changes how WriteZoneFile writes a zone, or how
+// ParseZoneFile reads one.
+type ZoneFileOption func(*zoneFile)
+
+type zoneFile struct {
+	aliasType string
+	apexNS    bool
+
+	// Where $INCLUDE paths are relative to, and how deep the includes go.
+	dir   string
+	depth int
+}
+
+// WithAliasType writes ALIAS records, which only Porkbun has, as records of
+// the given pseudo-type, such as "ALIAS" or "ANAME", for servers that support
+// one. By default, they are written as comments. When parsing, records of the
+// pseudo-type are read as ALIAS records, as ALIAS records always are.
+func WithAliasType(recordType string) ZoneFileOption {
+	return func(z *zoneFile) {
+		z.aliasType = strings.ToUpper(recordType)
+	}
+}
+
+// WithApexNS keeps the NS records of the domain itself when parsing. They are
+// skipped by default, as Porkbun serves its own, and the name servers of a
+// domain are set with UpdateNameServers instead.
+func WithApexNS() ZoneFileOption {
+	return func(z *zoneFile) {
+		z.apexNS = true
+	}
+}
+
+// WriteZoneFile writes records as an RFC 1035 master file for domain, as
+// read by BIND and most other DNS servers.
+//
+// The file starts with $ORIGIN and a $TTL of the most common TTL. Names in the
+// domain are written relative to it, and other names absolutely, with a
+// trailing dot. TXT values are quoted and split into strings of up to 255
+// bytes, and the priority of MX and SRV records is written into the record
+// data. ALIAS records are written as comments, unless WithAliasType is given.
+// Notes are written as comments after their record.
+//
+// There is no SOA record, as Porkbun does not expose one; the server loading
+// the file has to add its own.
+func WriteZoneFile(w io.Writer, domain string, records []Record, options ...ZoneFileOption) error {
+	z := &zoneFile{}
+	for _, option := range options {
+		option(z)
+	}
+
+	domain, err := ToASCII(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return err
+	}
+	domain = strings.ToLower(domain)
+
+	sorted := make([]Record, len(records))
+	copy(sorted, records)
+	for i := range sorted {
+		sorted[i].Name = zoneOwner(sorted[i].Name, domain)
+		sorted[i].Type = strings.ToUpper(sorted[i].Type)
+		sorted[i].TTL = max(sorted[i].TTL, defaultTTL)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Name != b.Name {
+			// The apex comes first.
+			return a.Name == "@" || (b.Name != "@" && a.Name < b.Name)
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Priority < b.Priority
+	})
+
+	ttl := commonTTL(sorted)
+
+	if _, err := fmt.Fprintf(w, "$ORIGIN %s.\n$TTL %d\n\n", domain, ttl); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, record := range sorted {
+		data, err := zoneData(record, domain)
+		if err != nil {
+			return fmt.Errorf("err writing %s record %q, %w", record.Type, record.Name, err)
+		}
+
+		recordType := record.Type
+		prefix := ""
+		if recordType == "ALIAS" {
+			if z.aliasType == "" {
+				prefix = "; "
+			} else {
+				recordType = z.aliasType
+			}
+		}
+
+		line := prefix + record.Name + "\t"
+		if record.TTL != ttl {
+			line += strconv.FormatUint(uint64(record.TTL), 10)
+		}
+		line += "\tIN\t" + recordType + "\t" + data
+
+		if record.Notes != "" {
+			line += "\t; " + strings.Join(strings.Fields(record.Notes), " ")
+		}
+
+		if _, err := fmt.Fprintln(tw, line); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// commonTTL returns the TTL most records have, preferring the lowest on a tie.
+func commonTTL(records []Record) TTL {
+	counts := map[TTL]int{}
+	for _, record := range records {
+		counts[record.TTL]++
+	}
+
+	common := defaultTTL
+	for ttl, n := range counts {
+		if n > counts[common] || (n == counts[common] && ttl < common) {
+			common = ttl
+		}
+	}
+
+	return common
+}
+
+// zoneOwner returns the owner name of a record relative to domain, or "@" for
+// the domain itself. Porkbun returns names with the domain, but names without
+// it are taken to be relative already.
+func zoneOwner(name, domain string) string {
+	owner := relativeName(strings.ToLower(strings.TrimSuffix(name, ".")), domain)
+	if owner == "" {
+		return "@"
+	}
+
+	return owner
+}
+
+// zoneHost writes a host name from record content: relative to domain if it
+// is under it, and absolutely otherwise.
+func zoneHost(host, domain string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	switch {
+	case host == "":
+		return "."
+	case strings.HasSuffix(host, "."+domain):
+		return strings.TrimSuffix(host, "."+domain)
+	}
+
+	return host + "."
+}
+
+// zoneData returns the record data of a record in master file form.
+func zoneData(record Record, domain string) (string, error) {
+	switch record.Type {
+	case "CNAME", "ALIAS", "NS":
+		return zoneHost(record.Content, domain), nil
+	case "MX":
+		return fmt.Sprintf("%d %s", record.Priority, zoneHost(record.Content, domain)), nil
+	case "SRV":
+		data, err := parseSRV(record)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d %d %d %s", data.Priority, data.Weight, data.Port, zoneHost(data.Target, domain)), nil
+	case "HTTPS", "SVCB":
+		data, err := parseSVCB(record.Type, record.Content)
+		if err != nil {
+			return "", err
+		}
+		if data.Target != "." {
+			data.Target = zoneHost(data.Target, domain)
+		}
+		return data.ToRecord("").Content, nil
+	case "CAA":
+		data, err := parseCAA(record.Content)
+		if err != nil {
+			return "", err
+		}
+		return data.ToRecord("").Content, nil
+	case "TXT":
+		return zoneText(record.TextValue()), nil
+	}
+
+	return record.Content, nil
+}
+
+// zoneText quotes a TXT value as strings of up to 255 bytes, split between
+// UTF-8 characters like SplitText. Quotes and backslashes are escaped, and so
+// are control characters, as \DDD.
+func zoneText(value string) string {
+	var chunks []string
+	for {
+		end := min(len(value), maxTXTChunk)
+		for end < len(value) && end > 0 && !utf8.RuneStart(value[end]) {
+			end--
+		}
+
+		chunks = append(chunks, escapeZoneText(value[:end]))
+		value = value[end:]
+
+		if value == "" {
+			break
+		}
+	}
+
+	return strings.Join(chunks, " ")
+}
+
+func escapeZoneText(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c == 0x7f:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// The most $INCLUDE files may be nested.
+const maxZoneIncludes = 8
+
+// ParseZoneFile reads the records of domain from an RFC 1035 master file, such
+// as one written by WriteZoneFile or exported from another DNS server.
+//
+// $ORIGIN, $TTL and $INCLUDE are followed, and a record may span lines inside
+// parentheses. Names are relative to the origin unless they end in a dot. A
+// record without an owner belongs to the one before it, and one without a TTL
+// has the $TTL, or else the TTL of the record before it. Included files are
+// opened relative to the working directory; see LoadZoneFile.
+//
+// Records come back as CreateDnsRecord takes them: named relative to domain,
+// with the host names in their content absolute and without a trailing dot,
+// and the priority of MX and SRV records in Priority. The SOA record is
+// skipped, as Porkbun has none, and so are NS records at the apex, unless
+// WithApexNS is given. Records outside domain, of a class other than IN, or
+// of a type Porkbun does not support are an error.
+func ParseZoneFile(r io.Reader, domain string, options ...ZoneFileOption) ([]Record, error) {
+	z := &zoneFile{}
+	for _, option := range options {
+		option(z)
+	}
+
+	return z.parse(r, domain)
+}
+
+// LoadZoneFile reads a master file with ParseZoneFile, with $INCLUDE paths
+// relative to the directory of the file.
+func LoadZoneFile(name, domain string, options ...ZoneFileOption) ([]Record, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("err opening zone file, %w", err)
+	}
+	defer f.Close()
+
+	z := &zoneFile{dir: filepath.Dir(name)}
+	for _, option := range options {
+		option(z)
+	}
+
+	return z.parse(f, domain)
+}
+
+func (z *zoneFile) parse(r io.Reader, domain string) ([]Record, error) {
+	domain, err := ToASCII(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return nil, err
+	}
+	domain = strings.ToLower(domain)
+
+	p := &zoneParser{zoneFile: z, domain: domain, origin: domain}
+	if err := p.read(r); err != nil {
+		return nil, err
+	}
+
+	return p.records, nil
+}
+
+// zoneParser holds what a master file has said so far, as it is read.
+type zoneParser struct {
+	*zoneFile
+	domain string
+
+	origin    string
+	lastOwner string
+
+	// The TTL set by $TTL, and the last TTL given on a record.
+	ttl     TTL
+	hasTTL  bool
+	lastTTL TTL
+
+	records []Record
+}
+
+func (p *zoneParser) read(r io.Reader) error {
+	entries, err := zoneEntries(r)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := p.entry(entry); err != nil {
+			return fmt.Errorf("line %d, %w", entry.line, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *zoneParser) entry(e zoneEntry) error {
+	tokens := e.tokens
+	if !e.blankOwner && !tokens[0].quoted && strings.HasPrefix(tokens[0].text, "$") {
+		return p.directive(strings.ToUpper(tokens[0].text), tokens[1:])
+	}
+
+	if !e.blankOwner {
+		p.lastOwner = p.absolute(tokens[0].text)
+		tokens = tokens[1:]
+	} else if p.lastOwner == "" {
+		return fmt.Errorf("the first record has no owner")
+	}
+	owner := p.lastOwner
+
+	// The TTL and the class are optional, and come in either order.
+	var ttl TTL
+	var hasTTL bool
+	for len(tokens) > 0 && !tokens[0].quoted {
+		if t, err := parseZoneTTL(tokens[0].text); err == nil {
+			ttl, hasTTL = t, true
+		} else if class := strings.ToUpper(tokens[0].text); class == "CH" || class == "HS" || class == "CS" {
+			return fmt.Errorf("class %s is not supported, only IN", class)
+		} else if class != "IN" {
+			break
+		}
+		tokens = tokens[1:]
+	}
+
+	switch {
+	case hasTTL:
+		p.lastTTL = ttl
+	case p.hasTTL:
+		ttl = p.ttl
+	case p.lastTTL != 0:
+		ttl = p.lastTTL
+	default:
+		ttl = defaultTTL
+	}
+
+	if len(tokens) == 0 {
+		return fmt.Errorf("record has no type")
+	}
+	recordType := strings.ToUpper(tokens[0].text)
+	if recordType == p.aliasType {
+		recordType = "ALIAS"
+	}
+
+	if recordType == "SOA" || (recordType == "NS" && owner == p.domain && !p.apexNS) {
+		return nil
+	}
+
+	if owner != p.domain && !strings.HasSuffix(owner, "."+p.domain) {
+		return fmt.Errorf("%q is not in %q", owner, p.domain)
+	}
+
+	record, err := p.record(recordType, tokens[1:])
+	if err != nil {
+		return fmt.Errorf("invalid %s record, %w", recordType, err)
+	}
+	record.Name = relativeName(owner, p.domain)
+	record.TTL = ttl
+
+	p.records = append(p.records, record)
+
+	return nil
+}
+
+func (p *zoneParser) directive(name string, args []zoneToken) error {
+	switch name {
+	case "$ORIGIN":
+		if len(args) != 1 {
+			return fmt.Errorf("want \"$ORIGIN name\"")
+		}
+		p.origin = p.absolute(args[0].text)
+	case "$TTL":
+		if len(args) != 1 {
+			return fmt.Errorf("want \"$TTL ttl\"")
+		}
+		ttl, err := parseZoneTTL(args[0].text)
+		if err != nil {
+			return err
+		}
+		p.ttl, p.hasTTL = ttl, true
+	case "$INCLUDE":
+		if len(args) != 1 && len(args) != 2 {
+			return fmt.Errorf("want \"$INCLUDE file [origin]\"")
+		}
+		return p.include(args[0].text, args[1:])
+	default:
+		return fmt.Errorf("%s is not supported", name)
+	}
+
+	return nil
+}
+
+// include reads the records of another file. The origin and the owner go back
+// to what they were once it is read, as RFC 1035 says.
+func (p *zoneParser) include(name string, origin []zoneToken) error {
+	if p.depth >= maxZoneIncludes {
+		return fmt.Errorf("$INCLUDE nested more than %d deep", maxZoneIncludes)
+	}
+
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(p.dir, name)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("err opening $INCLUDE file, %w", err)
+	}
+	defer f.Close()
+
+	savedOrigin, savedOwner := p.origin, p.lastOwner
+	if len(origin) > 0 {
+		p.origin = p.absolute(origin[0].text)
+	}
+	p.depth++
+
+	err = p.read(f)
+
+	p.origin, p.lastOwner = savedOrigin, savedOwner
+	p.depth--
+
+	if err != nil {
+		return fmt.Errorf("err reading $INCLUDE %q, %w", name, err)
+	}
+
+	return nil
+}
+
+// absolute returns a name from the file fully qualified, without the trailing
+// dot. "@" is the origin, and names without a trailing dot are relative to it.
+func (p *zoneParser) absolute(name string) string {
+	name = strings.ToLower(name)
+
+	switch {
+	case name == "@":
+		return p.origin
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case p.origin == "":
+		return name
+	}
+
+	return name + "." + p.origin
+}
+
+// host returns a host name from record data in the form Porkbun keeps it.
+func (p *zoneParser) host(name string) string {
+	if name == "." {
+		return name
+	}
+
+	return p.absolute(name)
+}
+
+// record builds a record from the record data of a master file.
+func (p *zoneParser) record(recordType string, rdata []zoneToken) (Record, error) {
+	if _, ok := contentChecks[recordType]; !ok {
+		return Record{}, fmt.Errorf("the type is not supported by Porkbun")
+	}
+
+	fields := make([]string, len(rdata))
+	for i, token := range rdata {
+		fields[i] = token.text
+	}
+
+	count := func(n int, form string) error {
+		if len(fields) != n {
+			return fmt.Errorf("want %q", form)
+		}
+		return nil
+	}
+
+	switch recordType {
+	case "TXT":
+		if len(fields) == 0 {
+			return Record{}, fmt.Errorf("want one or more strings")
+		}
+		return Record{Type: recordType, Content: SplitText(strings.Join(fields, ""))}, nil
+	case "CNAME", "ALIAS", "NS":
+		if err := count(1, "host"); err != nil {
+			return Record{}, err
+		}
+		return Record{Type: recordType, Content: p.host(fields[0])}, nil
+	case "MX":
+		if err := count(2, "priority host"); err != nil {
+			return Record{}, err
+		}
+		priority, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid priority, %w", err)
+		}
+		data := MXData{Priority: Priority(priority), Host: p.host(fields[1])}
+		return data.ToRecord(""), nil
+	case "SRV":
+		if err := count(4, "priority weight port target"); err != nil {
+			return Record{}, err
+		}
+		priority, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid priority, %w", err)
+		}
+		data, err := parseSRV(Record{Content: strings.Join(fields[1:], " "), Priority: Priority(priority)})
+		if err != nil {
+			return Record{}, err
+		}
+		data.Target = p.host(data.Target)
+		return data.ToRecord(""), nil
+	case "CAA":
+		if err := count(3, "flag tag value"); err != nil {
+			return Record{}, err
+		}
+		flag, err := strconv.ParseUint(fields[0], 10, 8)
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid flag, %w", err)
+		}
+		data := CAAData{Flag: uint8(flag), Tag: fields[1], Value: fields[2]}
+		return data.ToRecord(""), nil
+	case "TLSA":
+		if len(fields) < 4 {
+			return Record{}, fmt.Errorf("want \"usage selector matching-type data\"")
+		}
+		// The data may be split into several fields.
+		data, err := parseTLSA(strings.Join(fields[:3], " ") + " " + strings.Join(fields[3:], ""))
+		if err != nil {
+			return Record{}, err
+		}
+		return data.ToRecord(""), nil
+	case "HTTPS", "SVCB":
+		data, err := parseSVCB(recordType, strings.Join(fields, " "))
+		if err != nil {
+			return Record{}, err
+		}
+		data.Target = p.host(data.Target)
+		return data.ToRecord(""), nil
+	}
+
+	if err := count(1, "address"); err != nil {
+		return Record{}, err
+	}
+	return Record{Type: recordType, Content: fields[0]}, nil
+}
+
+// parseZoneTTL reads a TTL in seconds, or in the units BIND allows, as in
+// 1h30m.
+func parseZoneTTL(s string) (TTL, error) {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return 0, fmt.Errorf("invalid ttl %q", s)
+	}
+
+	units := map[byte]uint64{'s': 1, 'm': 60, 'h': 60 * 60, 'd': 24 * 60 * 60, 'w': 7 * 24 * 60 * 60}
+
+	var total, n uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			n = n*10 + uint64(s[i]-'0')
+		case units[c] != 0 && i > 0 && s[i-1] >= '0' && s[i-1] <= '9':
+			total += n * units[c]
+			n = 0
+		default:
+			return 0, fmt.Errorf("invalid ttl %q", s)
+		}
+
+		if n > math.MaxUint32 || total > math.MaxUint32 {
+			return 0, fmt.Errorf("invalid ttl %q, it is too long", s)
+		}
+	}
+
+	total += n
+	if total > math.MaxUint32 {
+		return 0, fmt.Errorf("invalid ttl %q, it is too long", s)
+	}
+
+	return TTL(total), nil
+}
+
+// zoneEntry is a record or a directive from a master file: the tokens of a
+// line, or of several lines joined by parentheses.
+type zoneEntry struct {
+	line int
+
+	// Whether the entry starts with a blank, leaving out the owner.
+	blankOwner bool
+
+	tokens []zoneToken
+}
+
+type zoneToken struct {
+	// The token, unescaped.
+	text   string
+	quoted bool
+}
+
+// zoneEntries splits a master file into entries, leaving out comments and
+// blank lines.
+func zoneEntries(r io.Reader) ([]zoneEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+
+	var entries []zoneEntry
+	var entry zoneEntry
+	var depth int
+
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if depth == 0 {
+			entry = zoneEntry{line: n, blankOwner: strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")}
+		}
+
+		if err := entry.scan(line, &depth); err != nil {
+			return nil, fmt.Errorf("line %d, %w", n, err)
+		}
+
+		if depth == 0 && len(entry.tokens) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("err reading zone file, %w", err)
+	}
+
+	if depth > 0 {
+		return nil, fmt.Errorf("line %d, missing closing parenthesis", entry.line)
+	}
+
+	return entries, nil
+}
+
+// scan adds the tokens of a line to the entry, keeping count of the
+// parentheses left open.
+func (e *zoneEntry) scan(line string, depth *int) error {
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t', '\r':
+			i++
+		case ';':
+			return nil
+		case '(':
+			*depth++
+			i++
+		case ')':
+			if *depth == 0 {
+				return fmt.Errorf("unexpected closing parenthesis")
+			}
+			*depth--
+			i++
+		default:
+			token, end, err := readZoneToken(line, i)
+			if err != nil {
+				return err
+			}
+			e.tokens = append(e.tokens, token)
+			i = end
+		}
+	}
+
+	return nil
+}
+
+// readZoneToken reads the token starting at line[i], and returns it with the
+// index after it. A quoted token ends at its closing quote, and others at a
+// blank, a comment or a parenthesis. \X and \DDD escapes are unescaped.
+func readZoneToken(line string, i int) (zoneToken, int, error) {
+	quoted := line[i] == '"'
+	if quoted {
+		i++
+	}
+
+	var b strings.Builder
+	for ; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quoted && c == '"':
+			return zoneToken{text: b.String(), quoted: true}, i + 1, nil
+		case !quoted && strings.IndexByte(" \t\r;()", c) >= 0:
+			return zoneToken{text: b.String()}, i, nil
+		case c != '\\':
+			b.WriteByte(c)
+		case i+3 < len(line) && isNumber(line[i+1:i+4]):
+			n, _ := strconv.Atoi(line[i+1 : i+4])
+			if n > 255 {
+				return zoneToken{}, 0, fmt.Errorf("escape \\%s is not a byte", line[i+1:i+4])
+			}
+			b.WriteByte(byte(n))
+			i += 3
+		case i+1 < len(line):
+			b.WriteByte(line[i+1])
+			i++
+		default:
+			return zoneToken{}, 0, fmt.Errorf("escape at the end of the line")
+		}
+	}
+
+	if quoted {
+		return zoneToken{}, 0, fmt.Errorf("missing closing quote")
+	}
+
+	return zoneToken{text: b.String()}, i, nil
+}
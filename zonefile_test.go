@@ -0,0 +1,227 @@
+package porkbun_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andrew-womeldorf/porkbun-go"
+)
+
+func TestWriteZoneFile(t *testing.T) {
+	records := []porkbun.Record{
+		{Name: "www.example.com", Type: "CNAME", Content: "example.com", TTL: 600},
+		{Name: "example.com", Type: "A", Content: "192.0.2.1", TTL: 600},
+		{Name: "example.com", Type: "mx", Content: "mail.example.net", TTL: 3600, Priority: 10},
+		{Name: "example.com", Type: "MX", Content: "mx.example.com", TTL: 3600, Priority: 5},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "5 5060 sip.example.com", Priority: 20},
+		{Name: "example.com", Type: "TXT", Content: `say "hi"`, TTL: 600, Notes: "greeting\nfor all"},
+		{Name: "long.example.com", Type: "TXT", Content: porkbun.SplitText(strings.Repeat("a", 300)), TTL: 600},
+		{Name: "example.com", Type: "ALIAS", Content: "lb.example.net", TTL: 600},
+		{Name: "example.com", Type: "CAA", Content: "0 issue letsencrypt.org", TTL: 600},
+		{Name: "example.com", Type: "HTTPS", Content: "1 svc.example.net alpn=h2", TTL: 600},
+	}
+
+	want := `$ORIGIN example.com.
+$TTL 600
+
+@              IN A     192.0.2.1
+; @            IN ALIAS lb.example.net.
+@              IN CAA   0 issue "letsencrypt.org"
+@              IN HTTPS 1 svc.example.net. alpn=h2
+@         3600 IN MX    5 mx
+@         3600 IN MX    10 mail.example.net.
+@              IN TXT   "say \"hi\"" ; greeting for all
+_sip._tcp      IN SRV   20 5 5060 sip
+long           IN TXT   "` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"
+www            IN CNAME example.com.
+`
+
+	var b strings.Builder
+	if err := porkbun.WriteZoneFile(&b, "example.com.", records); err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	t.Run("alias type", func(t *testing.T) {
+		var b strings.Builder
+		if err := porkbun.WriteZoneFile(&b, "example.com", records[7:8], porkbun.WithAliasType("aname")); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if !strings.Contains(b.String(), "@  IN ANAME lb.example.net.\n") {
+			t.Errorf("got\n%s\nwant an ANAME record", b.String())
+		}
+	})
+
+	t.Run("control characters", func(t *testing.T) {
+		var b strings.Builder
+		records := []porkbun.Record{{Name: "example.com", Type: "TXT", Content: "a\tb\\c"}}
+		if err := porkbun.WriteZoneFile(&b, "example.com", records); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if !strings.Contains(b.String(), `"a\009b\\c"`) {
+			t.Errorf("got\n%s\nwant the tab and backslash escaped", b.String())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		records := []porkbun.Record{{Name: "_sip._tcp.example.com", Type: "SRV", Content: "sip.example.com"}}
+		if err := porkbun.WriteZoneFile(&strings.Builder{}, "example.com", records); err == nil {
+			t.Error("got nil, want an error for invalid SRV content")
+		}
+	})
+}
+
+const zoneFile = `; A zone, as another server might export it
+$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	ns1.example.net. hostmaster.example.com. (
+		2024010101 ; serial
+		7200       ; refresh
+		3600       ; retry
+		1209600    ; expire
+		300 )      ; minimum
+	IN	NS	ns1.example.net.
+	IN	NS	ns2.example.net.
+	IN	A	192.0.2.1
+	IN	MX	10 mail
+	IN	MX	20 mail.example.net.
+	IN	TXT	"v=spf1 mx -all"
+www	600	IN	CNAME	@
+_sip._tcp	SRV	5 10 5060 sip.example.com.
+long	TXT	( "first part, "
+		  "second part" )
+quote	TXT	"say \"hi\"\059 bye"
+tlsa	TLSA	3 1 1 ( 0123456789abcdef
+		0123456789ABCDEF )
+caa	IN 900 CAA 0 issue "letsencrypt.org"
+lb	ALIAS	lb.example.net.
+svc	HTTPS	1 . alpn=h2
+sub	NS	ns1.example.net.
+$ORIGIN dev.example.com.
+api	A	192.0.2.2
+`
+
+func TestParseZoneFile(t *testing.T) {
+	want := []porkbun.Record{
+		{Name: "", Type: "A", Content: "192.0.2.1", TTL: 3600},
+		{Name: "", Type: "MX", Content: "mail.example.com", TTL: 3600, Priority: 10},
+		{Name: "", Type: "MX", Content: "mail.example.net", TTL: 3600, Priority: 20},
+		{Name: "", Type: "TXT", Content: "v=spf1 mx -all", TTL: 3600},
+		{Name: "www", Type: "CNAME", Content: "example.com", TTL: 600},
+		{Name: "_sip._tcp", Type: "SRV", Content: "10 5060 sip.example.com", TTL: 3600, Priority: 5},
+		{Name: "long", Type: "TXT", Content: "first part, second part", TTL: 3600},
+		{Name: "quote", Type: "TXT", Content: `say "hi"; bye`, TTL: 3600},
+		{Name: "tlsa", Type: "TLSA", Content: "3 1 1 0123456789abcdef0123456789abcdef", TTL: 3600},
+		{Name: "caa", Type: "CAA", Content: `0 issue "letsencrypt.org"`, TTL: 900},
+		{Name: "lb", Type: "ALIAS", Content: "lb.example.net", TTL: 3600},
+		{Name: "svc", Type: "HTTPS", Content: "1 . alpn=h2", TTL: 3600},
+		{Name: "sub", Type: "NS", Content: "ns1.example.net", TTL: 3600},
+		{Name: "api.dev", Type: "A", Content: "192.0.2.2", TTL: 3600},
+	}
+
+	got, err := porkbun.ParseZoneFile(strings.NewReader(zoneFile), "example.com")
+	if err != nil {
+		t.Fatalf("got %s, want nil", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%+v\nwant\n%+v", got, want)
+	}
+
+	t.Run("apex ns", func(t *testing.T) {
+		got, err := porkbun.ParseZoneFile(strings.NewReader(zoneFile), "example.com", porkbun.WithApexNS())
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if len(got) != len(want)+2 || got[0].Type != "NS" || got[0].Content != "ns1.example.net" {
+			t.Errorf("got %+v, want the apex NS records first", got)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		var b strings.Builder
+		if err := porkbun.WriteZoneFile(&b, "example.com", got, porkbun.WithAliasType("ANAME")); err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		again, err := porkbun.ParseZoneFile(strings.NewReader(b.String()), "example.com", porkbun.WithAliasType("ANAME"))
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		if len(again) != len(got) {
+			t.Fatalf("got %d records, want %d\n%s", len(again), len(got), b.String())
+		}
+		for _, record := range got {
+			found := false
+			for _, other := range again {
+				if reflect.DeepEqual(record, other) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("got no %+v after a round trip\n%s", record, b.String())
+			}
+		}
+	})
+
+	t.Run("include", func(t *testing.T) {
+		dir := t.TempDir()
+		main := "$TTL 600\n$INCLUDE mail.db mail.example.com.\n@ A 192.0.2.1\n"
+		if err := os.WriteFile(filepath.Join(dir, "example.com.db"), []byte(main), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "mail.db"), []byte("@ A 192.0.2.25\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := porkbun.LoadZoneFile(filepath.Join(dir, "example.com.db"), "example.com")
+		if err != nil {
+			t.Fatalf("got %s, want nil", err)
+		}
+
+		want := []porkbun.Record{
+			{Name: "mail", Type: "A", Content: "192.0.2.25", TTL: 600},
+			{Name: "", Type: "A", Content: "192.0.2.1", TTL: 600},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := []struct {
+			msg  string
+			zone string
+		}{
+			{msg: "outside the domain", zone: "www.example.net. A 192.0.2.1\n"},
+			{msg: "unsupported type", zone: "@ PTR host.example.com.\n"},
+			{msg: "unsupported class", zone: "@ CH A 192.0.2.1\n"},
+			{msg: "no owner", zone: "  A 192.0.2.1\n"},
+			{msg: "unclosed parenthesis", zone: "@ TXT ( \"a\"\n"},
+			{msg: "unopened parenthesis", zone: "@ TXT \"a\" )\n"},
+			{msg: "unclosed quote", zone: "@ TXT \"a\n"},
+			{msg: "bad mx", zone: "@ MX mail\n"},
+			{msg: "bad ttl", zone: "$TTL 1x\n"},
+			{msg: "missing include", zone: "$INCLUDE missing.db\n"},
+			{msg: "unsupported directive", zone: "$GENERATE 1-10 host$ A 192.0.2.$\n"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.msg, func(t *testing.T) {
+				if got, err := porkbun.ParseZoneFile(strings.NewReader(tc.zone), "example.com"); err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+			})
+		}
+	})
+}